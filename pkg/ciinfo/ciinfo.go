@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+// Package ciinfo detects the CI provider the process is running in and
+// exposes facts about the current build in a provider independent way.
+package ciinfo
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Provider is a CI provider.
+type Provider string
+
+const (
+	// Local is not running in CI.
+	Local Provider = "local"
+	// GitHubActions is GitHub Actions.
+	GitHubActions Provider = "github-actions"
+	// GitLabCI is GitLab CI/CD.
+	GitLabCI Provider = "gitlab-ci"
+	// Jenkins is Jenkins.
+	Jenkins Provider = "jenkins"
+	// Generic is unrecognized CI setting CI environment variable.
+	Generic Provider = "ci"
+)
+
+// Info describes the current build.
+type Info struct {
+	Provider Provider
+	// Repository e.g. "happy-sdk/addons", empty when unknown.
+	Repository string
+	// Branch built, source branch of pull requests.
+	Branch string
+	// Tag built, empty for branch builds.
+	Tag    string
+	Commit string
+	// PR is number of pull or merge request built, zero otherwise.
+	PR int
+	// BuildURL is URL of the build or job.
+	BuildURL string
+	// TokenEnv is name of first set environment variable holding API
+	// token of the forge, empty when no token is available.
+	TokenEnv string
+}
+
+// IsCI reports whether build is running in CI.
+func (i Info) IsCI() bool {
+	return i.Provider != Local
+}
+
+// Interactive reports whether user can be prompted, false in CI.
+func (i Info) Interactive() bool {
+	return !i.IsCI()
+}
+
+// HasToken reports whether API token is available.
+func (i Info) HasToken() bool {
+	return i.TokenEnv != ""
+}
+
+// IsPR reports whether pull or merge request is built.
+func (i Info) IsPR() bool {
+	return i.PR > 0
+}
+
+// Annotations reports whether provider renders workflow command
+// annotations printed to stdout.
+func (i Info) Annotations() bool {
+	return i.Provider == GitHubActions
+}
+
+// Detect detects CI provider from process environment.
+func Detect() Info {
+	return DetectEnv(os.Getenv)
+}
+
+// DetectEnv detects CI provider from environment looked up with getenv.
+func DetectEnv(getenv func(string) string) Info {
+	var info Info
+	switch {
+	case getenv("GITHUB_ACTIONS") == "true":
+		info = Info{
+			Provider:   GitHubActions,
+			Repository: getenv("GITHUB_REPOSITORY"),
+			Commit:     getenv("GITHUB_SHA"),
+		}
+		if run := getenv("GITHUB_RUN_ID"); run != "" {
+			info.BuildURL = strings.TrimSuffix(getenv("GITHUB_SERVER_URL"), "/") + "/" +
+				info.Repository + "/actions/runs/" + run
+		}
+		ref := getenv("GITHUB_REF")
+		switch {
+		case strings.HasPrefix(ref, "refs/tags/"):
+			info.Tag = strings.TrimPrefix(ref, "refs/tags/")
+		case strings.HasPrefix(ref, "refs/pull/"):
+			// refs/pull/<number>/merge
+			number, _, _ := strings.Cut(strings.TrimPrefix(ref, "refs/pull/"), "/")
+			info.PR = atoi(number)
+			info.Branch = getenv("GITHUB_HEAD_REF")
+		default:
+			info.Branch = strings.TrimPrefix(ref, "refs/heads/")
+		}
+		info.TokenEnv = tokenEnv(getenv, "GITHUB_TOKEN", "GH_TOKEN")
+
+	case getenv("GITLAB_CI") == "true":
+		info = Info{
+			Provider:   GitLabCI,
+			Repository: getenv("CI_PROJECT_PATH"),
+			Tag:        getenv("CI_COMMIT_TAG"),
+			Commit:     getenv("CI_COMMIT_SHA"),
+			PR:         atoi(getenv("CI_MERGE_REQUEST_IID")),
+			BuildURL:   getenv("CI_JOB_URL"),
+		}
+		if info.PR > 0 {
+			info.Branch = getenv("CI_MERGE_REQUEST_SOURCE_BRANCH_NAME")
+		} else {
+			info.Branch = getenv("CI_COMMIT_BRANCH")
+		}
+		// job token has limited API access, personal tokens are preferred
+		info.TokenEnv = tokenEnv(getenv, "GITLAB_TOKEN", "GL_TOKEN", "CI_JOB_TOKEN")
+
+	case getenv("JENKINS_URL") != "":
+		info = Info{
+			Provider: Jenkins,
+			Tag:      getenv("TAG_NAME"),
+			Commit:   getenv("GIT_COMMIT"),
+			// CHANGE_ID is set by multibranch pipelines of pull requests
+			PR:       atoi(getenv("CHANGE_ID")),
+			BuildURL: getenv("BUILD_URL"),
+		}
+		switch {
+		case info.PR > 0:
+			info.Branch = getenv("CHANGE_BRANCH")
+		case getenv("BRANCH_NAME") != "":
+			info.Branch = getenv("BRANCH_NAME")
+		default:
+			// GIT_BRANCH of git plugin includes remote name
+			branch := getenv("GIT_BRANCH")
+			if _, b, ok := strings.Cut(branch, "/"); ok {
+				branch = b
+			}
+			info.Branch = branch
+		}
+		info.TokenEnv = tokenEnv(getenv, "GITHUB_TOKEN", "GH_TOKEN", "GITLAB_TOKEN", "GL_TOKEN")
+
+	default:
+		info.Provider = Local
+		if ci := getenv("CI"); ci != "" && ci != "false" && ci != "0" {
+			info.Provider = Generic
+		}
+		info.TokenEnv = tokenEnv(getenv, "GITHUB_TOKEN", "GH_TOKEN", "GITLAB_TOKEN", "GL_TOKEN")
+	}
+	return info
+}
+
+// tokenEnv returns first of names set in environment.
+func tokenEnv(getenv func(string) string, names ...string) string {
+	for _, name := range names {
+		if getenv(name) != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+func atoi(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package ciinfo
+
+import "testing"
+
+func TestDetectEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want Info
+	}{
+		{
+			name: "local",
+			env:  map[string]string{"GH_TOKEN": "t"},
+			want: Info{Provider: Local, TokenEnv: "GH_TOKEN"},
+		},
+		{
+			name: "generic",
+			env:  map[string]string{"CI": "1"},
+			want: Info{Provider: Generic},
+		},
+		{
+			name: "github pull request",
+			env: map[string]string{
+				"GITHUB_ACTIONS":    "true",
+				"GITHUB_REPOSITORY": "happy-sdk/addons",
+				"GITHUB_SERVER_URL": "https://github.com",
+				"GITHUB_RUN_ID":     "42",
+				"GITHUB_SHA":        "abc",
+				"GITHUB_REF":        "refs/pull/7/merge",
+				"GITHUB_HEAD_REF":   "feature",
+				"GITHUB_TOKEN":      "t",
+			},
+			want: Info{
+				Provider:   GitHubActions,
+				Repository: "happy-sdk/addons",
+				Branch:     "feature",
+				Commit:     "abc",
+				PR:         7,
+				BuildURL:   "https://github.com/happy-sdk/addons/actions/runs/42",
+				TokenEnv:   "GITHUB_TOKEN",
+			},
+		},
+		{
+			name: "github tag",
+			env: map[string]string{
+				"GITHUB_ACTIONS": "true",
+				"GITHUB_REF":     "refs/tags/v1.2.0",
+			},
+			want: Info{Provider: GitHubActions, Tag: "v1.2.0"},
+		},
+		{
+			name: "gitlab",
+			env: map[string]string{
+				"GITLAB_CI":        "true",
+				"CI":               "true",
+				"CI_PROJECT_PATH":  "happy-sdk/addons",
+				"CI_COMMIT_BRANCH": "main",
+				"CI_JOB_TOKEN":     "t",
+			},
+			want: Info{Provider: GitLabCI, Repository: "happy-sdk/addons", Branch: "main", TokenEnv: "CI_JOB_TOKEN"},
+		},
+		{
+			name: "jenkins",
+			env: map[string]string{
+				"JENKINS_URL": "https://jenkins.example.com/",
+				"GIT_BRANCH":  "origin/release/v1",
+				"BUILD_URL":   "https://jenkins.example.com/job/app/3/",
+			},
+			want: Info{Provider: Jenkins, Branch: "release/v1", BuildURL: "https://jenkins.example.com/job/app/3/"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectEnv(func(key string) string { return tt.env[key] })
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if got.Interactive() != (tt.want.Provider == Local) {
+				t.Errorf("unexpected interactive %v", got.Interactive())
+			}
+		})
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/happy-sdk/addons/pkg/ciinfo
+
+go 1.21.5
@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package coverage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const defaultCodecovURL = "https://codecov.io"
+
+// Build identifies the CI build coverage belongs to.
+type Build struct {
+	// Slug is repository "owner/repo".
+	Slug   string
+	Commit string
+	Branch string
+	// PR is pull request number, empty outside of pull request builds.
+	PR string
+	// Service is CI service name e.g. "github-actions".
+	Service string
+	// Number is CI build number, used to group parallel jobs.
+	Number string
+	// Job is CI job id.
+	Job string
+	URL string
+}
+
+// Codecov uploads coverage reports with Codecov v4 upload protocol.
+type Codecov struct {
+	url   string
+	token string
+	http  *http.Client
+}
+
+// NewCodecov returns uploader to Codecov at url, codecov.io when
+// empty. Token may be empty for public repositories on supported CI.
+func NewCodecov(url, token string) *Codecov {
+	if url == "" {
+		url = defaultCodecovURL
+	}
+	return &Codecov{url: strings.TrimSuffix(url, "/"), token: token, http: &http.Client{Timeout: uploadTimeout}}
+}
+
+// Upload uploads profile flagged with flag, returns url of the report.
+func (c *Codecov) Upload(ctx context.Context, b Build, flag string, p *Profile) (string, error) {
+	q := url.Values{}
+	q.Set("package", "happy-sdk-coverage-addon")
+	q.Set("commit", b.Commit)
+	q.Set("branch", b.Branch)
+	q.Set("slug", b.Slug)
+	q.Set("service", b.Service)
+	q.Set("build", b.Number)
+	q.Set("job", b.Job)
+	q.Set("build_url", b.URL)
+	q.Set("pr", b.PR)
+	q.Set("flags", flag)
+	q.Set("name", flag)
+	if c.token != "" {
+		q.Set("token", c.token)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/upload/v4?"+q.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/plain")
+	body, err := c.send(req)
+	if err != nil {
+		return "", err
+	}
+	// response is report url followed by storage url to put report to
+	reportURL, storageURL, ok := strings.Cut(strings.TrimSpace(body), "\n")
+	if !ok {
+		return "", fmt.Errorf("%w: codecov: unexpected response %q", ErrUpload, body)
+	}
+
+	var report bytes.Buffer
+	report.WriteString("<<<<<< network\n# path=coverage.out\n")
+	if err := p.Format(&report); err != nil {
+		return "", err
+	}
+	report.WriteString("<<<<<< EOF\n")
+
+	put, err := http.NewRequestWithContext(ctx, http.MethodPut, strings.TrimSpace(storageURL), &report)
+	if err != nil {
+		return "", err
+	}
+	put.Header.Set("Content-Type", "text/plain")
+	put.Header.Set("x-amz-acl", "public-read")
+	if _, err := c.send(put); err != nil {
+		return "", err
+	}
+	return reportURL, nil
+}
+
+func (c *Codecov) send(req *http.Request) (string, error) {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: codecov: %s", ErrUpload, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("%w: codecov: %s", ErrUpload, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("%w: codecov: %d %s", ErrUpload, resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return string(body), nil
+}
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package coverage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/happy-sdk/happy"
+	"github.com/happy-sdk/happy/sdk/settings"
+)
+
+type Settings struct {
+	Service settings.String `key:"service" default:"codecov" mutation:"once"`
+	URL     settings.String `key:"url" mutation:"once"`
+	Token   settings.String `key:"token" mutation:"once"`
+}
+
+func (s Settings) Blueprint() (*settings.Blueprint, error) {
+	b, err := settings.New(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Coverage is the API provided by the coverage addon.
+type Coverage struct {
+	happy.API
+
+	mu       sync.Mutex
+	uploader Uploader
+}
+
+func Addon(s Settings) *happy.Addon {
+	addon := happy.NewAddon("coverage", s)
+
+	api := &Coverage{}
+	addon.ProvidesAPI(api)
+
+	addon.OnRegister(func(sess *happy.Session) error {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		url, token := setting(sess, "url"), setting(sess, "token")
+		switch service := setting(sess, "service"); service {
+		case ServiceCodecov:
+			if token == "" {
+				token = os.Getenv("CODECOV_TOKEN")
+			}
+			api.uploader = NewCodecov(url, token)
+		case ServiceCoveralls:
+			if token == "" {
+				token = os.Getenv("COVERALLS_REPO_TOKEN")
+			}
+			wd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			api.uploader = NewCoveralls(url, token, wd)
+		default:
+			return fmt.Errorf("%w: %q", ErrNoService, service)
+		}
+		return nil
+	})
+
+	return addon
+}
+
+// Upload uploads merged coverage profile at path flagged per module
+// using build of the current CI job.
+func (c *Coverage) Upload(ctx context.Context, path string, modules []Module) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p, err := ParseProfile(f)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	u := c.uploader
+	c.mu.Unlock()
+	return Upload(ctx, u, BuildFromEnv(), p, modules)
+}
+
+func setting(sess *happy.Session, key string) string {
+	return sess.Settings().Get("coverage." + key).Value().String()
+}
@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package coverage
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testProfile = `mode: atomic
+example.com/repo/a.go:3.14,5.2 1 2
+example.com/repo/sub/b.go:3.14,4.2 1 0
+example.com/repo/sub/b.go:5.14,6.2 1 1
+`
+
+var testModules = []Module{
+	{Path: "example.com/repo", Dir: "."},
+	{Path: "example.com/repo/sub", Dir: "sub"},
+}
+
+func TestProfileModule(t *testing.T) {
+	p, err := ParseProfile(strings.NewReader(testProfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ParseProfile(strings.NewReader("mode: atomic\nexample.com/repo/a.go:3.14,5.2 1 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Merge(other); err != nil {
+		t.Fatal(err)
+	}
+
+	root := p.Module(testModules[0], testModules)
+	if len(root.Blocks) != 1 || root.Blocks[0].File != "a.go" || root.Blocks[0].Count != 5 {
+		t.Errorf("unexpected root blocks %+v", root.Blocks)
+	}
+	sub := p.Module(testModules[1], testModules)
+	if len(sub.Blocks) != 2 || sub.Blocks[0].File != "sub/b.go" {
+		t.Errorf("unexpected sub blocks %+v", sub.Blocks)
+	}
+	if f := testModules[1].Flag(); f != "sub" {
+		t.Errorf("unexpected flag %q", f)
+	}
+
+	if _, err := ParseProfile(strings.NewReader("mode: set\nx.go:1.1,2 1 1\n")); err == nil {
+		t.Error("expected malformed block error")
+	}
+}
+
+func TestCodecovUpload(t *testing.T) {
+	var flags []string
+	var reports []string
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/upload/v4":
+			if r.URL.Query().Get("token") != "tok" || r.URL.Query().Get("commit") != "abc" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			flags = append(flags, r.URL.Query().Get("flags"))
+			_, _ = w.Write([]byte("https://codecov.example/report\n" + srv.URL + "/storage\n"))
+		case r.Method == http.MethodPut && r.URL.Path == "/storage":
+			data, _ := io.ReadAll(r.Body)
+			reports = append(reports, string(data))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	}))
+	defer srv.Close()
+
+	p, err := ParseProfile(strings.NewReader(testProfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	urls, err := Upload(context.Background(), NewCodecov(srv.URL, "tok"), Build{Commit: "abc"}, p, testModules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flags) != 2 || flags[0] != "root" || flags[1] != "sub" || urls["sub"] != "https://codecov.example/report" {
+		t.Errorf("unexpected flags %v, urls %v", flags, urls)
+	}
+	if !strings.Contains(reports[1], "sub/b.go:5.14,6.2 1 1\n<<<<<< EOF") {
+		t.Errorf("unexpected report\n%s", reports[1])
+	}
+}
+
+func TestCoverallsUpload(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.go", "sub/b.go"} {
+		src := "package x\n\nfunc f() {\n\t_ = 1\n}\nfunc g() {}\n"
+		if err := os.WriteFile(filepath.Join(root, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var jobs []coverallsJob
+	finished := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/jobs":
+			f, _, err := r.FormFile("json_file")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var job coverallsJob
+			_ = json.NewDecoder(f).Decode(&job)
+			jobs = append(jobs, job)
+			_, _ = w.Write([]byte(`{"message":"Job ##1.1","url":"https://coveralls.example/jobs/1"}`))
+		case "/webhook":
+			finished = true
+			_, _ = w.Write([]byte(`{"done":true}`))
+		}
+	}))
+	defer srv.Close()
+
+	p, err := ParseProfile(strings.NewReader(testProfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Upload(context.Background(), NewCoveralls(srv.URL, "tok", root), Build{Commit: "abc"}, p, testModules); err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 || !finished {
+		t.Fatalf("expected 2 parallel jobs and finished build, got %d jobs, finished %t", len(jobs), finished)
+	}
+	cov := jobs[1].SourceFiles[0].Coverage
+	if jobs[1].FlagName != "sub" || len(cov) != 7 || cov[0] != nil || *cov[2] != 0 || *cov[4] != 1 {
+		t.Errorf("unexpected job %+v", jobs[1])
+	}
+}
@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package coverage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const defaultCoverallsURL = "https://coveralls.io"
+
+type coverallsFile struct {
+	Name         string `json:"name"`
+	SourceDigest string `json:"source_digest"`
+	Coverage     []*int `json:"coverage"`
+}
+
+type coverallsJob struct {
+	RepoToken    string          `json:"repo_token,omitempty"`
+	ServiceName  string          `json:"service_name"`
+	ServiceJobID string          `json:"service_job_id,omitempty"`
+	ServiceNum   string          `json:"service_number,omitempty"`
+	ServicePR    string          `json:"service_pull_request,omitempty"`
+	FlagName     string          `json:"flag_name,omitempty"`
+	Parallel     bool            `json:"parallel"`
+	Git          coverallsGit    `json:"git"`
+	SourceFiles  []coverallsFile `json:"source_files"`
+}
+
+type coverallsGit struct {
+	Head struct {
+		ID string `json:"id"`
+	} `json:"head"`
+	Branch string `json:"branch"`
+}
+
+// Coveralls uploads coverage as parallel Coveralls jobs, one per flag,
+// which are combined by Finish.
+type Coveralls struct {
+	url   string
+	token string
+	// root is repository root source files are read from.
+	root string
+	http *http.Client
+}
+
+// NewCoveralls returns uploader to Coveralls at url, coveralls.io when
+// empty, reading sources of repository at root.
+func NewCoveralls(url, token, root string) *Coveralls {
+	if url == "" {
+		url = defaultCoverallsURL
+	}
+	return &Coveralls{url: strings.TrimSuffix(url, "/"), token: token, root: root, http: &http.Client{Timeout: uploadTimeout}}
+}
+
+// Upload uploads profile as parallel job flagged with flag, returns
+// url of the job.
+func (c *Coveralls) Upload(ctx context.Context, b Build, flag string, p *Profile) (string, error) {
+	job := coverallsJob{
+		RepoToken:    c.token,
+		ServiceName:  b.Service,
+		ServiceJobID: b.Job,
+		ServiceNum:   b.Number,
+		ServicePR:    b.PR,
+		FlagName:     flag,
+		Parallel:     true,
+	}
+	job.Git.Head.ID = b.Commit
+	job.Git.Branch = b.Branch
+
+	lines := p.Lines()
+	for _, name := range p.Files() {
+		src, err := os.ReadFile(filepath.Join(c.root, filepath.FromSlash(name)))
+		if err != nil {
+			return "", fmt.Errorf("%w: coveralls: %s", ErrUpload, err)
+		}
+		sum := md5.Sum(src)
+		// coverage has an entry for every source line, profile lines are 1-based
+		cov := make([]*int, bytes.Count(src, []byte("\n"))+1)
+		for l, hits := range lines[name] {
+			if l > 0 && l <= len(cov) {
+				cov[l-1] = hits
+			}
+		}
+		job.SourceFiles = append(job.SourceFiles, coverallsFile{
+			Name:         name,
+			SourceDigest: hex.EncodeToString(sum[:]),
+			Coverage:     cov,
+		})
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return "", err
+	}
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("json_file", "coverage.json")
+	if err != nil {
+		return "", err
+	}
+	if _, err := fw.Write(data); err != nil {
+		return "", err
+	}
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/api/v1/jobs", &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	var out struct {
+		URL string `json:"url"`
+	}
+	if err := c.send(req, &out); err != nil {
+		return "", err
+	}
+	return out.URL, nil
+}
+
+// Finish closes parallel build combining uploaded jobs.
+func (c *Coveralls) Finish(ctx context.Context, b Build) error {
+	payload := map[string]any{
+		"repo_token":     c.token,
+		"service_name":   b.Service,
+		"service_number": b.Number,
+		"payload": map[string]string{
+			"build_num": b.Number,
+			"status":    "done",
+		},
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/webhook", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.send(req, nil)
+}
+
+func (c *Coveralls) send(req *http.Request, out any) error {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: coveralls: %s", ErrUpload, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%w: coveralls: %s", ErrUpload, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w: coveralls: %d %s", ErrUpload, resp.StatusCode, bytes.TrimSpace(body))
+	}
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("%w: coveralls: %s", ErrUpload, err)
+		}
+	}
+	return nil
+}
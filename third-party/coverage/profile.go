@@ -0,0 +1,222 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package coverage
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	Error        = errors.New("coverage")
+	ErrProfile   = fmt.Errorf("%w: invalid profile", Error)
+	ErrUpload    = fmt.Errorf("%w: upload failed", Error)
+	ErrNoService = fmt.Errorf("%w: unknown service", Error)
+)
+
+// Block is a profile block of Go coverage profile.
+type Block struct {
+	File      string
+	StartLine int
+	StartCol  int
+	EndLine   int
+	EndCol    int
+	NumStmt   int
+	Count     int
+}
+
+// Profile is a Go coverage profile, possibly merged from profiles
+// of multiple modules.
+type Profile struct {
+	Mode   string
+	Blocks []Block
+}
+
+// Module is a Go module of the repository.
+type Module struct {
+	// Path is module path e.g. "github.com/happy-sdk/addons/third-party/github".
+	Path string
+	// Dir is module directory relative to repository root, "." for
+	// the root module.
+	Dir string
+}
+
+// Flag returns coverage flag of module, its directory with path
+// separators replaced as flags may not contain slashes.
+func (m Module) Flag() string {
+	if m.Dir == "." || m.Dir == "" {
+		return "root"
+	}
+	return strings.ReplaceAll(m.Dir, "/", "-")
+}
+
+// ParseProfile parses Go coverage profile written by go test -coverprofile.
+func ParseProfile(r io.Reader) (*Profile, error) {
+	p := &Profile{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if mode, ok := strings.CutPrefix(line, "mode: "); ok {
+			if p.Mode != "" && p.Mode != mode {
+				return nil, fmt.Errorf("%w: line %d: mixed modes %s and %s", ErrProfile, n, p.Mode, mode)
+			}
+			p.Mode = mode
+			continue
+		}
+		b, err := parseBlock(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %s", ErrProfile, n, err)
+		}
+		p.Blocks = append(p.Blocks, b)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if p.Mode == "" {
+		return nil, fmt.Errorf("%w: missing mode line", ErrProfile)
+	}
+	return p, nil
+}
+
+// parseBlock parses "file.go:12.34,15.2 3 1".
+func parseBlock(line string) (Block, error) {
+	i := strings.LastIndex(line, ":")
+	if i < 0 {
+		return Block{}, fmt.Errorf("missing file in %q", line)
+	}
+	b := Block{File: line[:i]}
+	var err error
+	fields := strings.FieldsFunc(line[i+1:], func(r rune) bool {
+		return r == '.' || r == ',' || r == ' '
+	})
+	if len(fields) != 6 {
+		return Block{}, fmt.Errorf("malformed block %q", line)
+	}
+	for j, dst := range []*int{&b.StartLine, &b.StartCol, &b.EndLine, &b.EndCol, &b.NumStmt, &b.Count} {
+		if *dst, err = strconv.Atoi(fields[j]); err != nil {
+			return Block{}, fmt.Errorf("malformed block %q", line)
+		}
+	}
+	return b, nil
+}
+
+// Merge adds blocks of other profile, counts of identical blocks are
+// summed, or or-ed in set mode.
+func (p *Profile) Merge(other *Profile) error {
+	if p.Mode == "" {
+		p.Mode = other.Mode
+	}
+	if other.Mode != p.Mode {
+		return fmt.Errorf("%w: can not merge %s profile into %s profile", ErrProfile, other.Mode, p.Mode)
+	}
+	type key struct {
+		file                                 string
+		startLine, startCol, endLine, endCol int
+	}
+	index := make(map[key]int, len(p.Blocks))
+	for i, b := range p.Blocks {
+		index[key{b.File, b.StartLine, b.StartCol, b.EndLine, b.EndCol}] = i
+	}
+	for _, b := range other.Blocks {
+		k := key{b.File, b.StartLine, b.StartCol, b.EndLine, b.EndCol}
+		i, ok := index[k]
+		switch {
+		case !ok:
+			index[k] = len(p.Blocks)
+			p.Blocks = append(p.Blocks, b)
+		case p.Mode == "set":
+			if b.Count > 0 {
+				p.Blocks[i].Count = 1
+			}
+		default:
+			p.Blocks[i].Count += b.Count
+		}
+	}
+	return nil
+}
+
+// Module returns blocks of files in module with file names rewritten from
+// import paths to paths relative to repository root. With nested modules
+// file is attributed to the module with the longest matching path.
+func (p *Profile) Module(m Module, all []Module) *Profile {
+	out := &Profile{Mode: p.Mode}
+	for _, b := range p.Blocks {
+		if owner, ok := moduleOf(b.File, all); !ok || owner.Path != m.Path {
+			continue
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(b.File, m.Path), "/")
+		b.File = path.Join(m.Dir, rel)
+		out.Blocks = append(out.Blocks, b)
+	}
+	return out
+}
+
+func moduleOf(file string, modules []Module) (Module, bool) {
+	var best Module
+	found := false
+	for _, m := range modules {
+		if strings.HasPrefix(file, m.Path+"/") && len(m.Path) > len(best.Path) {
+			best, found = m, true
+		}
+	}
+	return best, found
+}
+
+// Format writes profile in Go coverage profile format.
+func (p *Profile) Format(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "mode: %s\n", p.Mode); err != nil {
+		return err
+	}
+	for _, b := range p.Blocks {
+		if _, err := fmt.Fprintf(w, "%s:%d.%d,%d.%d %d %d\n",
+			b.File, b.StartLine, b.StartCol, b.EndLine, b.EndCol, b.NumStmt, b.Count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lines returns per line hit counts of every file, line number is the
+// index. Lines without statements are nil.
+func (p *Profile) Lines() map[string][]*int {
+	files := make(map[string][]*int)
+	for _, b := range p.Blocks {
+		lines := files[b.File]
+		for len(lines) <= b.EndLine {
+			lines = append(lines, nil)
+		}
+		for l := b.StartLine; l <= b.EndLine; l++ {
+			if lines[l] == nil || *lines[l] < b.Count {
+				c := b.Count
+				lines[l] = &c
+			}
+		}
+		files[b.File] = lines
+	}
+	return files
+}
+
+// Files returns sorted file names of profile.
+func (p *Profile) Files() []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, b := range p.Blocks {
+		if !seen[b.File] {
+			seen[b.File] = true
+			files = append(files, b.File)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
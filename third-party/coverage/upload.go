@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package coverage
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+)
+
+// uploadTimeout limits requests of uploaders, so stalled service does not
+// hang the release.
+const uploadTimeout = 2 * time.Minute
+
+// Services.
+const (
+	ServiceCodecov   = "codecov"
+	ServiceCoveralls = "coveralls"
+)
+
+// Uploader uploads coverage profile of a single flag.
+type Uploader interface {
+	Upload(ctx context.Context, b Build, flag string, p *Profile) (string, error)
+}
+
+// Upload uploads coverage of every module of merged profile flagged with
+// module flag, modules without coverage are skipped. Parallel Coveralls
+// build is finished after all modules are uploaded. Returns report urls
+// by flag.
+func Upload(ctx context.Context, u Uploader, b Build, p *Profile, modules []Module) (map[string]string, error) {
+	urls := make(map[string]string, len(modules))
+	for _, m := range modules {
+		mp := p.Module(m, modules)
+		if len(mp.Blocks) == 0 {
+			continue
+		}
+		url, err := u.Upload(ctx, b, m.Flag(), mp)
+		if err != nil {
+			return urls, err
+		}
+		urls[m.Flag()] = url
+	}
+	if c, ok := u.(*Coveralls); ok && len(urls) > 0 {
+		if err := c.Finish(ctx, b); err != nil {
+			return urls, err
+		}
+	}
+	return urls, nil
+}
+
+// BuildFromEnv returns build of the current CI job, supporting GitHub
+// Actions and GitLab CI.
+func BuildFromEnv() Build {
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		b := Build{
+			Service: "github-actions",
+			Slug:    os.Getenv("GITHUB_REPOSITORY"),
+			Commit:  os.Getenv("GITHUB_SHA"),
+			Branch:  os.Getenv("GITHUB_REF_NAME"),
+			Number:  os.Getenv("GITHUB_RUN_ID"),
+			Job:     os.Getenv("GITHUB_JOB"),
+			URL: os.Getenv("GITHUB_SERVER_URL") + "/" + os.Getenv("GITHUB_REPOSITORY") +
+				"/actions/runs/" + os.Getenv("GITHUB_RUN_ID"),
+		}
+		// refs/pull/<number>/merge
+		if ref, ok := strings.CutPrefix(os.Getenv("GITHUB_REF"), "refs/pull/"); ok {
+			b.PR, _, _ = strings.Cut(ref, "/")
+			b.Branch = os.Getenv("GITHUB_HEAD_REF")
+		}
+		return b
+	case os.Getenv("GITLAB_CI") == "true":
+		return Build{
+			Service: "gitlab",
+			Slug:    os.Getenv("CI_PROJECT_PATH"),
+			Commit:  os.Getenv("CI_COMMIT_SHA"),
+			Branch:  os.Getenv("CI_COMMIT_REF_NAME"),
+			PR:      os.Getenv("CI_MERGE_REQUEST_IID"),
+			Number:  os.Getenv("CI_PIPELINE_ID"),
+			Job:     os.Getenv("CI_JOB_ID"),
+			URL:     os.Getenv("CI_JOB_URL"),
+		}
+	}
+	return Build{Service: "local"}
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package deploy
+
+import (
+	"errors"
+	"io/fs"
+	"sync"
+
+	"github.com/happy-sdk/happy"
+	"github.com/happy-sdk/happy/sdk/settings"
+)
+
+type Settings struct {
+	ConfigFile settings.String `key:"config_file" default:".happy/deploy.json" mutation:"once"`
+}
+
+func (s Settings) Blueprint() (*settings.Blueprint, error) {
+	b, err := settings.New(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Deploy is the API provided by the deploy addon.
+type Deploy struct {
+	happy.API
+
+	mu       sync.Mutex
+	deployer *Deployer
+}
+
+func Addon(s Settings) *happy.Addon {
+	addon := happy.NewAddon("deploy", s)
+
+	api := &Deploy{}
+	addon.ProvidesAPI(api)
+
+	addon.OnRegister(func(sess *happy.Session) error {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		cfg, err := LoadConfig(setting(sess, "config_file"))
+		if errors.Is(err, fs.ErrNotExist) {
+			// nothing to deploy
+			cfg, err = &Config{}, nil
+		}
+		if err != nil {
+			return err
+		}
+		api.deployer = NewDeployer(cfg)
+		return nil
+	})
+
+	return addon
+}
+
+// Deployer returns deployer of host groups defined in config file.
+func (d *Deploy) Deployer() *Deployer {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.deployer
+}
+
+func setting(sess *happy.Session, key string) string {
+	return sess.Settings().Get("deploy." + key).Value().String()
+}
@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package deploy
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// fakeTools returns deployer using fake rsync and ssh logging their
+// arguments to returned log file. ssh fails for host "bad".
+func fakeTools(t *testing.T, cfg *Config) (*Deployer, string) {
+	t.Helper()
+	dir := t.TempDir()
+	log := filepath.Join(dir, "calls")
+	scripts := map[string]string{
+		"rsync": `echo "rsync $@" >> ` + log,
+		"ssh": `echo "ssh $@" >> ` + log + `
+for a in "$@"; do [ "$a" = bad ] && { echo "connection refused"; exit 255; }; done
+echo "done"`,
+	}
+	d := NewDeployer(cfg)
+	for name, body := range scripts {
+		bin := filepath.Join(dir, name)
+		if err := os.WriteFile(bin, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if name == "rsync" {
+			d.rsync = bin
+		} else {
+			d.ssh = bin
+		}
+	}
+	return d, log
+}
+
+func TestDeploy(t *testing.T) {
+	cfg := &Config{Groups: map[string]Group{
+		"prod": {
+			Hosts:     []string{"deploy@a", "deploy@b"},
+			Port:      2222,
+			Path:      "/opt/app/",
+			Artifacts: []string{"*_linux_amd64.tar.gz"},
+			Parallel:  2,
+			Post:      []string{"systemctl restart app"},
+		},
+	}}
+	d, log := fakeTools(t, cfg)
+
+	results, err := d.Deploy(context.Background(), "prod", []string{
+		"dist/app_linux_amd64.tar.gz",
+		"dist/app_darwin_arm64.tar.gz",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[1].Host != "deploy@b" || results[1].Output != "done\n" ||
+		len(results[0].Files) != 1 || results[0].Files[0] != "app_linux_amd64.tar.gz" {
+		t.Errorf("unexpected results %+v", results)
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := strings.Split(strings.TrimSpace(string(data)), "\n")
+	sort.Strings(calls)
+	if len(calls) != 6 {
+		t.Fatalf("expected 6 calls, got\n%s", data)
+	}
+	if !strings.Contains(calls[0], "'-p' '2222'") || !strings.HasSuffix(calls[0], "dist/app_linux_amd64.tar.gz deploy@a:/opt/app/") {
+		t.Errorf("unexpected rsync call %q", calls[0])
+	}
+	if !strings.HasSuffix(calls[5], "-- systemctl restart app") {
+		t.Errorf("unexpected post deploy call %q", calls[5])
+	}
+}
+
+func TestDeployHostFailure(t *testing.T) {
+	cfg := &Config{Groups: map[string]Group{
+		"edge": {Hosts: []string{"bad", "good"}, Path: "/srv"},
+	}}
+	d, _ := fakeTools(t, cfg)
+
+	results, err := d.Deploy(context.Background(), "edge", []string{"app.zip"})
+	if !errors.Is(err, ErrDeployFail) || !strings.Contains(err.Error(), "1 of 2 hosts") {
+		t.Fatalf("expected single host failure, got %v", err)
+	}
+	if results[0].Err == nil || results[1].Err != nil {
+		t.Errorf("unexpected results %+v", results)
+	}
+	if _, err := d.Deploy(context.Background(), "missing", nil); !errors.Is(err, ErrNoGroup) {
+		t.Errorf("expected ErrNoGroup, got %v", err)
+	}
+}
+
+func TestDeployQuoting(t *testing.T) {
+	cfg := &Config{Groups: map[string]Group{
+		"home": {
+			Hosts:    []string{"deploy@a"},
+			Identity: "/keys/deploy key",
+			Path:     "~/it's app",
+		},
+	}}
+	d, log := fakeTools(t, cfg)
+	if _, err := d.Deploy(context.Background(), "home", []string{"app.zip"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := string(data)
+	for _, want := range []string{
+		`-- mkdir -p "$HOME"/'it'\''s app'`,
+		`-e '` + d.ssh + `' '-o' 'BatchMode=yes' '-i' '/keys/deploy key' app.zip deploy@a:it's app/`,
+	} {
+		if !strings.Contains(calls, want) {
+			t.Errorf("missing %q in\n%s", want, calls)
+		}
+	}
+
+	for _, arg := range []string{"plain", "it's", "$HOME `id` \\ \"x\""} {
+		out, err := exec.Command("sh", "-c", "printf %s "+shellQuote(arg)).Output()
+		if err != nil || string(out) != arg {
+			t.Errorf("shellQuote(%q) evaluated to %q: %v", arg, out, err)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	Error         = errors.New("deploy")
+	ErrNoGroup    = fmt.Errorf("%w: unknown host group", Error)
+	ErrDeployFail = fmt.Errorf("%w: deployment failed", Error)
+)
+
+// Group is a group of hosts deployed together.
+type Group struct {
+	// Hosts are ssh destinations "[user@]host".
+	Hosts []string `json:"hosts"`
+	// Port is ssh port, default port when zero.
+	Port int `json:"port,omitempty"`
+	// Identity is path of ssh private key.
+	Identity string `json:"identity,omitempty"`
+	// Path is remote directory artifacts are copied into.
+	Path string `json:"path"`
+	// Artifacts are glob patterns of artifacts selected for the group,
+	// matched against artifact file names. All artifacts when empty.
+	Artifacts []string `json:"artifacts,omitempty"`
+	// Parallel is how many hosts are deployed at once, 1 when zero.
+	Parallel int `json:"parallel,omitempty"`
+	// Post are shell commands run on host after artifacts are copied.
+	Post []string `json:"post,omitempty"`
+}
+
+// Config is deployment configuration of host groups.
+type Config struct {
+	Groups map[string]Group `json:"groups"`
+}
+
+// LoadConfig reads JSON deployment configuration from path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", Error, path, err)
+	}
+	for name, g := range cfg.Groups {
+		if len(g.Hosts) == 0 || g.Path == "" {
+			return nil, fmt.Errorf("%w: %s: group %s requires hosts and path", Error, path, name)
+		}
+	}
+	return cfg, nil
+}
+
+// Result is deployment result of a single host.
+type Result struct {
+	Host string
+	// Files are deployed artifact names.
+	Files []string
+	// Output is combined output of post deploy commands.
+	Output string
+	Err    error
+}
+
+// Deployer copies artifacts to hosts with rsync over ssh.
+type Deployer struct {
+	cfg   *Config
+	rsync string
+	ssh   string
+}
+
+// NewDeployer returns deployer of host groups in cfg.
+func NewDeployer(cfg *Config) *Deployer {
+	return &Deployer{cfg: cfg, rsync: "rsync", ssh: "ssh"}
+}
+
+// Deploy copies artifacts selected for group to every host of group and
+// runs post deploy commands there. Hosts are deployed in parallel up to
+// group parallelism, failure of one host does not stop the others.
+func (d *Deployer) Deploy(ctx context.Context, group string, artifacts []string) ([]Result, error) {
+	g, ok := d.cfg.Groups[group]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNoGroup, group)
+	}
+	files, err := selectArtifacts(g.Artifacts, artifacts)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: no artifacts selected for group %s", Error, group)
+	}
+
+	parallel := g.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+	results := make([]Result, len(g.Hosts))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, host := range g.Hosts {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = d.deployHost(ctx, g, host, files)
+		}(i, host)
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.Host, r.Err))
+		}
+	}
+	if len(errs) > 0 {
+		return results, fmt.Errorf("%w: %d of %d hosts: %w", ErrDeployFail, len(errs), len(results), errors.Join(errs...))
+	}
+	return results, nil
+}
+
+func (d *Deployer) deployHost(ctx context.Context, g Group, host string, files []string) Result {
+	r := Result{Host: host}
+	sshArgs := sshOptions(g)
+	remote := func(command string) ([]byte, error) {
+		return run(ctx, d.ssh, append(sshArgs[:len(sshArgs):len(sshArgs)], host, "--", command)...)
+	}
+
+	dir := strings.TrimSuffix(g.Path, "/")
+	if _, err := remote("mkdir -p " + remotePath(dir)); err != nil {
+		r.Err = err
+		return r
+	}
+	rsh := make([]string, 0, len(sshArgs)+1)
+	for _, arg := range append([]string{d.ssh}, sshArgs...) {
+		rsh = append(rsh, rsyncQuote(arg))
+	}
+	// protect remote path from word splitting by the remote shell
+	args := []string{"-az", "--protect-args", "-e", strings.Join(rsh, " ")}
+	args = append(args, files...)
+	args = append(args, host+":"+rsyncPath(dir)+"/")
+	if _, err := run(ctx, d.rsync, args...); err != nil {
+		r.Err = err
+		return r
+	}
+	for _, f := range files {
+		r.Files = append(r.Files, filepath.Base(f))
+	}
+
+	var out strings.Builder
+	for _, command := range g.Post {
+		o, err := remote(command)
+		out.Write(o)
+		if err != nil {
+			r.Err = fmt.Errorf("post deploy %q: %w", command, err)
+			break
+		}
+	}
+	r.Output = out.String()
+	return r
+}
+
+// sshOptions returns ssh options of group, non interactive so that
+// unknown or changed host keys fail instead of prompting.
+func sshOptions(g Group) []string {
+	opts := []string{"-o", "BatchMode=yes"}
+	if g.Port != 0 {
+		opts = append(opts, "-p", strconv.Itoa(g.Port))
+	}
+	if g.Identity != "" {
+		opts = append(opts, "-i", g.Identity)
+	}
+	return opts
+}
+
+// remotePath returns dir quoted for remote shell, leading "~/" is
+// expanded by the shell as home directory.
+func remotePath(dir string) string {
+	if dir == "~" {
+		return `"$HOME"`
+	}
+	if rest, ok := strings.CutPrefix(dir, "~/"); ok {
+		return `"$HOME"/` + shellQuote(rest)
+	}
+	return shellQuote(dir)
+}
+
+// rsyncPath returns dir as rsync remote path, where paths relative to
+// home directory are written without leading "~/".
+func rsyncPath(dir string) string {
+	if dir == "~" {
+		return "."
+	}
+	return strings.TrimPrefix(dir, "~/")
+}
+
+// shellQuote quotes s as single argument of POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// rsyncQuote quotes s as single argument of rsync -e command, which splits
+// on spaces and takes doubled quote inside quotes as literal quote.
+func rsyncQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// selectArtifacts returns artifacts whose file name matches any pattern.
+func selectArtifacts(patterns, artifacts []string) ([]string, error) {
+	if len(patterns) == 0 {
+		return artifacts, nil
+	}
+	var selected []string
+	for _, a := range artifacts {
+		for _, p := range patterns {
+			ok, err := filepath.Match(p, filepath.Base(a))
+			if err != nil {
+				return nil, fmt.Errorf("%w: artifact pattern %q: %s", Error, p, err)
+			}
+			if ok {
+				selected = append(selected, a)
+				break
+			}
+		}
+	}
+	sort.Strings(selected)
+	return selected, nil
+}
+
+func run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return out.Bytes(), fmt.Errorf("%s: %s: %s", filepath.Base(name), err, strings.TrimSpace(out.String()))
+	}
+	return out.Bytes(), nil
+}
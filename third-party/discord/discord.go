@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package discord
+
+import (
+	"sync"
+
+	"github.com/happy-sdk/happy"
+	"github.com/happy-sdk/happy/sdk/settings"
+)
+
+type Settings struct {
+	WebhookURL settings.String `key:"webhook_url" mutation:"once"`
+	Username   settings.String `key:"username" mutation:"once"`
+	Template   settings.String `key:"template" mutation:"once"`
+}
+
+func (s Settings) Blueprint() (*settings.Blueprint, error) {
+	b, err := settings.New(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Discord is the API provided by the discord addon.
+type Discord struct {
+	happy.API
+
+	mu       sync.Mutex
+	webhook  string
+	username string
+	template string
+}
+
+func Addon(s Settings) *happy.Addon {
+	addon := happy.NewAddon("discord", s)
+
+	api := &Discord{}
+	addon.ProvidesAPI(api)
+
+	addon.OnRegister(func(sess *happy.Session) error {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		api.webhook = setting(sess, "webhook_url")
+		api.username = setting(sess, "username")
+		api.template = setting(sess, "template")
+		// fail registration early on broken template
+		_, err := NewWebhook(api.webhook, api.username, api.template)
+		return err
+	})
+
+	return addon
+}
+
+// Webhook returns webhook posting release announcements as configured.
+func (d *Discord) Webhook() (*Webhook, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return NewWebhook(d.webhook, d.username, d.template)
+}
+
+func setting(sess *happy.Session, key string) string {
+	return sess.Settings().Get("discord." + key).Value().String()
+}
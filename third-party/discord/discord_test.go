@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestAnnounce(t *testing.T) {
+	var got Message
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"retry_after":0.001}`))
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	wh, err := NewWebhook(srv.URL, "releaser", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = wh.Announce(context.Background(), Release{
+		Project:   "happy",
+		Version:   "v1.2.0",
+		URL:       "https://example.com/r",
+		Changelog: "- fix",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("expected rate limited post to be retried, got %d calls", calls)
+	}
+	if got.Content != "**happy v1.2.0** released <https://example.com/r>" || got.Username != "releaser" {
+		t.Errorf("unexpected message %+v", got)
+	}
+	if len(got.Embeds) != 1 || got.Embeds[0].Description != "- fix" {
+		t.Errorf("unexpected embeds %+v", got.Embeds)
+	}
+}
+
+func TestMessageTemplate(t *testing.T) {
+	if _, err := NewWebhook("", "", "{{ .Project "); !errors.Is(err, ErrTemplate) {
+		t.Errorf("expected ErrTemplate, got %v", err)
+	}
+
+	wh, err := NewWebhook("", "", `{{ .Project }}: {{ join .Tags ", " }}`)
+	if err == nil {
+		_, err = wh.Message(Release{})
+	}
+	if !errors.Is(err, ErrTemplate) {
+		t.Errorf("expected ErrTemplate for undefined function, got %v", err)
+	}
+
+	wh, err = NewWebhook("", "", `{{ range .Tags }}{{ . }} {{ end }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := wh.Message(Release{Tags: []string{"a/v1.0.0", "b/v2.0.0"}, Changelog: strings.Repeat("x", 5000)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Content != "a/v1.0.0 b/v2.0.0 " {
+		t.Errorf("unexpected content %q", msg.Content)
+	}
+	if n := utf8.RuneCountInString(msg.Embeds[0].Description); n != maxDescription {
+		t.Errorf("expected description truncated to %d, got %d", maxDescription, n)
+	}
+}
@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	// maxContent is Discord message content limit.
+	maxContent = 2000
+	// maxDescription is Discord embed description limit.
+	maxDescription   = 4096
+	maxRateLimitWait = time.Minute
+)
+
+var (
+	Error         = errors.New("discord")
+	ErrNoWebhook  = fmt.Errorf("%w: no webhook configured", Error)
+	ErrTemplate   = fmt.Errorf("%w: invalid template", Error)
+	ErrPostFailed = fmt.Errorf("%w: post message failed", Error)
+)
+
+// DefaultTemplate renders release announcement content.
+const DefaultTemplate = `**{{ .Project }} {{ .Version }}** released{{ if .URL }} <{{ .URL }}>{{ end }}`
+
+// Release is the release manifest data announcements are rendered from.
+type Release struct {
+	Project   string
+	Version   string
+	Tags      []string
+	URL       string
+	Changelog string
+}
+
+// Embed is a Discord rich embed.
+type Embed struct {
+	Title       string `json:"title,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Description string `json:"description,omitempty"`
+	Color       int    `json:"color,omitempty"`
+}
+
+// Message is a Discord webhook message.
+type Message struct {
+	Content  string  `json:"content,omitempty"`
+	Username string  `json:"username,omitempty"`
+	Embeds   []Embed `json:"embeds,omitempty"`
+}
+
+// Webhook posts release announcements to Discord channel webhook.
+type Webhook struct {
+	url      string
+	username string
+	tmpl     *template.Template
+	http     *http.Client
+}
+
+// NewWebhook returns webhook posting to url with content rendered from
+// text/template tmpl, DefaultTemplate when empty.
+func NewWebhook(url, username, tmpl string) (*Webhook, error) {
+	if tmpl == "" {
+		tmpl = DefaultTemplate
+	}
+	t, err := template.New("discord").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrTemplate, err)
+	}
+	return &Webhook{
+		url:      url,
+		username: username,
+		tmpl:     t,
+		http:     &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Message renders announcement of r. Changelog is attached as embed.
+func (w *Webhook) Message(r Release) (Message, error) {
+	var content strings.Builder
+	if err := w.tmpl.Execute(&content, r); err != nil {
+		return Message{}, fmt.Errorf("%w: %s", ErrTemplate, err)
+	}
+	msg := Message{
+		Content:  truncate(content.String(), maxContent),
+		Username: w.username,
+	}
+	if r.Changelog != "" {
+		msg.Embeds = append(msg.Embeds, Embed{
+			Title:       r.Project + " " + r.Version,
+			URL:         r.URL,
+			Description: truncate(r.Changelog, maxDescription),
+		})
+	}
+	return msg, nil
+}
+
+// Announce posts announcement of r.
+func (w *Webhook) Announce(ctx context.Context, r Release) error {
+	if w.url == "" {
+		return ErrNoWebhook
+	}
+	msg, err := w.Message(r)
+	if err != nil {
+		return err
+	}
+	return w.Post(ctx, msg)
+}
+
+// Post posts msg to the webhook, waiting out rate limit once.
+func (w *Webhook) Post(ctx context.Context, msg Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	for retried := false; ; retried = true {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := w.http.Do(req)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrPostFailed, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusTooManyRequests && !retried {
+			var limit struct {
+				RetryAfter float64 `json:"retry_after"`
+			}
+			_ = json.Unmarshal(body, &limit)
+			if d := time.Duration(limit.RetryAfter * float64(time.Second)); d <= maxRateLimitWait {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(d):
+				}
+				continue
+			}
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("%w: %d %s", ErrPostFailed, resp.StatusCode, bytes.TrimSpace(body))
+		}
+		return nil
+	}
+}
+
+// truncate cuts s to at most n runes marking the cut with ellipsis.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,166 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package github
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// InActions reports whether process is running in GitHub Actions.
+func InActions() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
+// Annotation is a message attached to a source location in Actions UI.
+type Annotation struct {
+	Title     string
+	File      string
+	Line      int
+	EndLine   int
+	Col       int
+	EndColumn int
+	Message   string
+}
+
+// Workflow emits GitHub Actions workflow commands. When not running in
+// GitHub Actions all commands are no-op, so it is safe to use
+// unconditionally.
+type Workflow struct {
+	mu      sync.Mutex
+	w       io.Writer
+	enabled bool
+}
+
+// NewWorkflow returns workflow writing commands to w, usually os.Stdout.
+func NewWorkflow(w io.Writer) *Workflow {
+	return &Workflow{
+		w:       w,
+		enabled: InActions(),
+	}
+}
+
+// Enabled reports whether workflow commands are emitted.
+func (wf *Workflow) Enabled() bool {
+	return wf.enabled
+}
+
+// Error creates an error annotation.
+func (wf *Workflow) Error(a Annotation) {
+	wf.annotate("error", a)
+}
+
+// Warning creates a warning annotation.
+func (wf *Workflow) Warning(a Annotation) {
+	wf.annotate("warning", a)
+}
+
+// Notice creates a notice annotation.
+func (wf *Workflow) Notice(a Annotation) {
+	wf.annotate("notice", a)
+}
+
+// Group starts collapsible group of log lines, returned func ends it.
+func (wf *Workflow) Group(title string) (end func()) {
+	wf.command("group", "", title)
+	return func() {
+		wf.command("endgroup", "", "")
+	}
+}
+
+// diagnosticRe matches compiler style diagnostics "path:line[:col]: message"
+// as reported by go vet, golangci-lint and go test failures.
+var diagnosticRe = regexp.MustCompile(`^\s*([^\s:]+\.go):(\d+)(?::(\d+))?:\s+(.+)$`)
+
+// Diagnostics reads compiler style diagnostics from r and emits them as
+// annotations of level "error" or "warning". Lines which are not
+// diagnostics are ignored. Returns number of annotations emitted.
+//
+// Annotation files must be relative to repository root, dir is joined
+// with relative paths of diagnostics. It is the directory tool ran in
+// relative to repository root, or package directory for go test output
+// which reports paths relative to package directory.
+func (wf *Workflow) Diagnostics(r io.Reader, dir, level, title string) (int, error) {
+	n := 0
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		a, ok := ParseDiagnostic(scanner.Text())
+		if !ok {
+			continue
+		}
+		if dir != "" && !path.IsAbs(a.File) {
+			a.File = path.Join(filepath.ToSlash(dir), a.File)
+		}
+		a.Title = title
+		wf.annotate(level, a)
+		n++
+	}
+	return n, scanner.Err()
+}
+
+// ParseDiagnostic parses compiler style diagnostic line.
+func ParseDiagnostic(line string) (Annotation, bool) {
+	m := diagnosticRe.FindStringSubmatch(line)
+	if m == nil {
+		return Annotation{}, false
+	}
+	a := Annotation{
+		File:    m[1],
+		Message: m[4],
+	}
+	a.Line, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		a.Col, _ = strconv.Atoi(m[3])
+	}
+	return a, true
+}
+
+func (wf *Workflow) annotate(level string, a Annotation) {
+	var props []string
+	add := func(key, val string) {
+		if val != "" && val != "0" {
+			props = append(props, key+"="+escapeProperty(val))
+		}
+	}
+	add("title", a.Title)
+	add("file", a.File)
+	add("line", strconv.Itoa(a.Line))
+	add("endLine", strconv.Itoa(a.EndLine))
+	add("col", strconv.Itoa(a.Col))
+	add("endColumn", strconv.Itoa(a.EndColumn))
+	wf.command(level, strings.Join(props, ","), a.Message)
+}
+
+func (wf *Workflow) command(name, props, msg string) {
+	if !wf.enabled {
+		return
+	}
+	wf.mu.Lock()
+	defer wf.mu.Unlock()
+	if props != "" {
+		name += " " + props
+	}
+	fmt.Fprintf(wf.w, "::%s::%s\n", name, escapeData(msg))
+}
+
+func escapeData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+func escapeProperty(s string) string {
+	s = escapeData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
@@ -0,0 +1,211 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+)
+
+var ErrSecurityAlerts = fmt.Errorf("%w: open security alerts", Error)
+
+// Alert gate modes.
+const (
+	AlertsOff  = "off"
+	AlertsWarn = "warn"
+	AlertsFail = "fail"
+)
+
+// DependabotAlert is a Dependabot alert.
+type DependabotAlert struct {
+	Number     int    `json:"number"`
+	State      string `json:"state"`
+	HTMLURL    string `json:"html_url"`
+	Dependency struct {
+		Package struct {
+			Ecosystem string `json:"ecosystem"`
+			Name      string `json:"name"`
+		} `json:"package"`
+		ManifestPath string `json:"manifest_path"`
+	} `json:"dependency"`
+	SecurityAdvisory struct {
+		GHSAID   string `json:"ghsa_id"`
+		Summary  string `json:"summary"`
+		Severity string `json:"severity"`
+	} `json:"security_advisory"`
+}
+
+// CodeScanningAlert is a code scanning alert.
+type CodeScanningAlert struct {
+	Number  int    `json:"number"`
+	State   string `json:"state"`
+	HTMLURL string `json:"html_url"`
+	Rule    struct {
+		ID                    string `json:"id"`
+		Description           string `json:"description"`
+		SecuritySeverityLevel string `json:"security_severity_level"`
+	} `json:"rule"`
+	MostRecentInstance struct {
+		Location struct {
+			Path string `json:"path"`
+		} `json:"location"`
+	} `json:"most_recent_instance"`
+}
+
+// ListDependabotAlerts returns open Dependabot alerts of repository.
+func (c *Client) ListDependabotAlerts(ctx context.Context, owner, repo string) ([]DependabotAlert, error) {
+	return listAll[DependabotAlert](ctx, c, repoPath(owner, repo, "dependabot", "alerts")+"?state=open&per_page=100")
+}
+
+// ListCodeScanningAlerts returns open code scanning alerts of repository.
+func (c *Client) ListCodeScanningAlerts(ctx context.Context, owner, repo string) ([]CodeScanningAlert, error) {
+	return listAll[CodeScanningAlert](ctx, c, repoPath(owner, repo, "code-scanning", "alerts")+"?state=open&per_page=100")
+}
+
+// SecurityAlert is a Dependabot or code scanning alert reported by AlertsGate.
+type SecurityAlert struct {
+	Source   string
+	Severity string
+	Path     string
+	Summary  string
+	URL      string
+}
+
+func (a SecurityAlert) String() string {
+	return fmt.Sprintf("%s %s %s: %s (%s)", a.Source, a.Severity, a.Path, a.Summary, a.URL)
+}
+
+// AlertsGate is release precondition checking open security alerts
+// of the released module.
+type AlertsGate struct {
+	client *Client
+	owner  string
+	repo   string
+	// Mode is one of AlertsOff, AlertsWarn or AlertsFail.
+	Mode string
+	// Severity is minimum severity reported: low, medium, high or critical.
+	Severity string
+}
+
+// NewAlertsGate returns gate failing on critical alerts of owner/repo.
+func NewAlertsGate(client *Client, owner, repo string) *AlertsGate {
+	return &AlertsGate{
+		client:   client,
+		owner:    owner,
+		repo:     repo,
+		Mode:     AlertsFail,
+		Severity: "critical",
+	}
+}
+
+// Check returns open alerts at or above gate severity affecting module at
+// dir relative to repository root, "." for the root module. Alerts of
+// nested modules are attributed to root module as well. Returns
+// ErrSecurityAlerts when there are any and mode is AlertsFail. Alerts of
+// unknown severity are always reported. Sources not enabled for the
+// repository are skipped.
+func (g *AlertsGate) Check(ctx context.Context, dir string) ([]SecurityAlert, error) {
+	if g.Mode == AlertsOff {
+		return nil, nil
+	}
+	dir = path.Clean(dir)
+	min := severityRank(g.Severity)
+
+	var found []SecurityAlert
+	deps, err := g.client.ListDependabotAlerts(ctx, g.owner, g.repo)
+	if err != nil && !alertsDisabled(err) {
+		return nil, err
+	}
+	for _, a := range deps {
+		if !reported(a.SecurityAdvisory.Severity, min) || !inDir(dir, a.Dependency.ManifestPath) {
+			continue
+		}
+		found = append(found, SecurityAlert{
+			Source:   "dependabot",
+			Severity: severityName(a.SecurityAdvisory.Severity),
+			Path:     a.Dependency.ManifestPath,
+			Summary:  a.Dependency.Package.Name + ": " + a.SecurityAdvisory.Summary,
+			URL:      a.HTMLURL,
+		})
+	}
+
+	scans, err := g.client.ListCodeScanningAlerts(ctx, g.owner, g.repo)
+	if err != nil && !alertsDisabled(err) {
+		return nil, err
+	}
+	for _, a := range scans {
+		loc := a.MostRecentInstance.Location.Path
+		// rules without security severity are not security alerts
+		if a.Rule.SecuritySeverityLevel == "" {
+			continue
+		}
+		if !reported(a.Rule.SecuritySeverityLevel, min) || !inDir(dir, loc) {
+			continue
+		}
+		found = append(found, SecurityAlert{
+			Source:   "code-scanning",
+			Severity: severityName(a.Rule.SecuritySeverityLevel),
+			Path:     loc,
+			Summary:  a.Rule.ID + ": " + a.Rule.Description,
+			URL:      a.HTMLURL,
+		})
+	}
+
+	if len(found) > 0 && g.Mode == AlertsFail {
+		return found, fmt.Errorf("%w: %d %s or higher in %s", ErrSecurityAlerts, len(found), g.Severity, dir)
+	}
+	return found, nil
+}
+
+// alertsDisabled reports whether listing alerts failed because the alert
+// source is not enabled for the repository, GitHub answers 403 when
+// Dependabot alerts are disabled and 403 or 404 when code scanning is not
+// set up.
+func alertsDisabled(err error) bool {
+	var errResp *ErrorResponse
+	if !errors.As(err, &errResp) || errResp.rateLimited {
+		return false
+	}
+	code := errResp.Response.StatusCode
+	return code == http.StatusForbidden || code == http.StatusNotFound
+}
+
+// reported reports whether alert of severity is at or above min rank.
+// Unknown severities are always reported so that they block the release
+// instead of passing any threshold.
+func reported(severity string, min int) bool {
+	rank := severityRank(severity)
+	return rank == 0 || rank >= min
+}
+
+func severityName(severity string) string {
+	if severity == "" {
+		return "unknown"
+	}
+	return severity
+}
+
+// severityRank orders severities, unknown severity ranks 0.
+func severityRank(severity string) int {
+	switch strings.ToLower(severity) {
+	case "low":
+		return 1
+	case "medium", "moderate":
+		return 2
+	case "high":
+		return 3
+	case "critical":
+		return 4
+	}
+	return 0
+}
+
+func inDir(dir, p string) bool {
+	return dir == "." || p == dir || strings.HasPrefix(p, dir+"/")
+}
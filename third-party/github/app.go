@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrAppAuth = fmt.Errorf("%w: app authentication failed", Error)
+
+// AppTokenSource authenticates as a GitHub App installation. Installation
+// tokens are requested with app JWT and refreshed shortly before they expire.
+type AppTokenSource struct {
+	appID          int64
+	installationID int64
+	owner          string
+	repo           string
+	key            *rsa.PrivateKey
+	baseURL        string
+	http           *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewAppTokenSource returns token source for app installation. When
+// installationID is 0 the installation is looked up for owner/repo.
+// privateKey is PEM encoded key or path to PEM file.
+func NewAppTokenSource(appID, installationID int64, privateKey, owner, repo string) (*AppTokenSource, error) {
+	key, err := ParseAppPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &AppTokenSource{
+		appID:          appID,
+		installationID: installationID,
+		owner:          owner,
+		repo:           repo,
+		key:            key,
+		baseURL:        defaultBaseURL,
+		http:           &http.Client{Timeout: time.Minute},
+	}, nil
+}
+
+// SetBaseURL sets API base url used to request installation tokens.
+func (s *AppTokenSource) SetBaseURL(u string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.baseURL = u
+}
+
+// Token returns valid installation token, refreshing it when needed.
+func (s *AppTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Until(s.expires) > time.Minute {
+		return s.token, nil
+	}
+
+	jwt, err := s.jwt(time.Now())
+	if err != nil {
+		return "", err
+	}
+	client, err := NewClient(jwt, WithBaseURL(s.baseURL), WithHTTPClient(s.http))
+	if err != nil {
+		return "", err
+	}
+
+	if s.installationID == 0 {
+		req, err := client.NewRequest(ctx, http.MethodGet, repoPath(s.owner, s.repo, "installation"), nil)
+		if err != nil {
+			return "", err
+		}
+		var inst struct {
+			ID int64 `json:"id"`
+		}
+		if _, err := client.Do(req, &inst); err != nil {
+			return "", fmt.Errorf("%w: find installation of %s/%s: %w", ErrAppAuth, s.owner, s.repo, err)
+		}
+		s.installationID = inst.ID
+	}
+
+	req, err := client.NewRequest(ctx, http.MethodPost, fmt.Sprintf("app/installations/%d/access_tokens", s.installationID), nil)
+	if err != nil {
+		return "", err
+	}
+	var out struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if _, err := client.Do(req, &out); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrAppAuth, err)
+	}
+	s.token, s.expires = out.Token, out.ExpiresAt
+	return s.token, nil
+}
+
+// jwt returns app JSON Web Token signed with RS256, valid for 9 minutes.
+// Issued at is set 60 seconds in the past to allow for clock drift.
+func (s *AppTokenSource) jwt(now time.Time) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": fmt.Sprint(s.appID),
+	})
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", fmt.Errorf("%w: sign jwt: %s", ErrAppAuth, err)
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+// ParseAppPrivateKey parses PEM encoded RSA private key, key is read
+// from file when it is not PEM data.
+func ParseAppPrivateKey(key string) (*rsa.PrivateKey, error) {
+	data := []byte(key)
+	if !strings.Contains(key, "-----BEGIN") {
+		var err error
+		if data, err = os.ReadFile(key); err != nil {
+			return nil, fmt.Errorf("%w: read private key: %s", ErrAppAuth, err)
+		}
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: private key is not PEM encoded", ErrAppAuth)
+	}
+	if k, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return k, nil
+	}
+	k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: parse private key: %s", ErrAppAuth, err)
+	}
+	rsaKey, ok := k.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: private key is not RSA key", ErrAppAuth)
+	}
+	return rsaKey, nil
+}
@@ -0,0 +1,184 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ListReleaseAssets returns assets of release id.
+func (c *Client) ListReleaseAssets(ctx context.Context, owner, repo string, id int64) ([]ReleaseAsset, error) {
+	return listAll[ReleaseAsset](ctx, c, repoPath(owner, repo, "releases", fmt.Sprint(id), "assets")+"?per_page=100")
+}
+
+// DeleteReleaseAsset deletes asset id.
+func (c *Client) DeleteReleaseAsset(ctx context.Context, owner, repo string, id int64) error {
+	req, err := c.NewRequest(ctx, http.MethodDelete, repoPath(owner, repo, "releases", "assets", fmt.Sprint(id)), nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.Do(req, nil)
+	return err
+}
+
+// DownloadReleaseAsset writes content of asset id into w.
+func (c *Client) DownloadReleaseAsset(ctx context.Context, owner, repo string, id int64, w io.Writer) error {
+	req, err := c.NewRequest(ctx, http.MethodGet, repoPath(owner, repo, "releases", "assets", fmt.Sprint(id)), nil)
+	if err != nil {
+		return err
+	}
+	// Binary content is served through a redirect to a storage backend,
+	// Authorization header is not forwarded to other hosts by net/http.
+	req.Header.Set("Accept", "application/octet-stream")
+	_, err = c.Do(req, w)
+	return err
+}
+
+// UploadReleaseAsset uploads file at path as an asset of rel. Content type
+// is detected from the file extension or content. Uploads failing because
+// of flaky connections are retried, removing the partially uploaded
+// asset before the next attempt.
+func (c *Client) UploadReleaseAsset(ctx context.Context, rel *Release, path string) (*ReleaseAsset, error) {
+	contentType, err := detectContentType(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// upload_url is a hypermedia template e.g. ".../assets{?name,label}"
+	name := filepath.Base(path)
+	u, _, _ := strings.Cut(rel.UploadURL, "{")
+	u += "?name=" + url.QueryEscape(name)
+
+	wait := c.retryWait
+	for attempt := 0; ; attempt++ {
+		asset, err := c.uploadAsset(ctx, u, path, contentType)
+		if err == nil || attempt >= c.retries || !retryableUpload(err) {
+			return asset, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+
+		if err := c.deleteAssetNamed(ctx, rel, name); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) uploadAsset(ctx context.Context, u, path, contentType string) (*ReleaseAsset, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := c.NewRequest(ctx, http.MethodPost, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(f)
+	req.ContentLength = stat.Size()
+	req.Header.Set("Content-Type", contentType)
+
+	asset := &ReleaseAsset{}
+	if _, err := c.Do(req, asset); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// deleteAssetNamed removes leftover of failed upload, if there is any.
+func (c *Client) deleteAssetNamed(ctx context.Context, rel *Release, name string) error {
+	owner, repo, ok := releaseRepo(rel)
+	if !ok {
+		return nil
+	}
+	assets, err := c.ListReleaseAssets(ctx, owner, repo, rel.ID)
+	if err != nil {
+		return err
+	}
+	for _, a := range assets {
+		if a.Name == name {
+			return c.DeleteReleaseAsset(ctx, owner, repo, a.ID)
+		}
+	}
+	return nil
+}
+
+// releaseRepo extracts owner and repo from release upload url
+// ".../repos/{owner}/{repo}/releases/{id}/assets{?name,label}".
+func releaseRepo(rel *Release) (owner, repo string, ok bool) {
+	_, p, found := strings.Cut(rel.UploadURL, "/repos/")
+	if !found {
+		return "", "", false
+	}
+	parts := strings.SplitN(p, "/", 3)
+	if len(parts) < 3 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+func retryableUpload(err error) bool {
+	var errResp *ErrorResponse
+	if errors.As(err, &errResp) {
+		return errResp.Response.StatusCode >= http.StatusInternalServerError
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// artifactContentTypes are common dist artifact types which are not
+// guaranteed to be present in the system mime tables.
+var artifactContentTypes = map[string]string{
+	".gz":  "application/gzip",
+	".tgz": "application/gzip",
+	".zip": "application/zip",
+	".xz":  "application/x-xz",
+	".zst": "application/zstd",
+	".deb": "application/vnd.debian.binary-package",
+	".rpm": "application/x-rpm",
+	".sig": "application/pgp-signature",
+	".asc": "application/pgp-signature",
+	".txt": "text/plain; charset=utf-8",
+}
+
+func detectContentType(path string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ct, ok := artifactContentTypes[ext]; ok {
+		return ct, nil
+	}
+	if ct := mime.TypeByExtension(ext); ct != "" {
+		return ct, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	buf := make([]byte, 512)
+	n, err := f.Read(buf)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return http.DetectContentType(buf[:n]), nil
+}
@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package github
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// Attestation is an artifact attestation stored by GitHub.
+type Attestation struct {
+	RepositoryID int64           `json:"repository_id"`
+	Bundle       json.RawMessage `json:"bundle"`
+}
+
+// UploadAttestation stores Sigstore bundle, e.g. SLSA provenance or
+// signature, as artifact attestation of repository. Attestations are
+// associated with artifacts by subject digest recorded in the bundle,
+// which makes them verifiable with "gh attestation verify".
+func (c *Client) UploadAttestation(ctx context.Context, owner, repo string, bundle json.RawMessage) (int64, error) {
+	if !json.Valid(bundle) {
+		return 0, fmt.Errorf("%w: attestation bundle is not valid JSON", Error)
+	}
+	req, err := c.NewRequest(ctx, http.MethodPost, repoPath(owner, repo, "attestations"), map[string]json.RawMessage{
+		"bundle": bundle,
+	})
+	if err != nil {
+		return 0, err
+	}
+	var out struct {
+		ID int64 `json:"id"`
+	}
+	if _, err := c.Do(req, &out); err != nil {
+		return 0, err
+	}
+	return out.ID, nil
+}
+
+// UploadAttestationFile uploads Sigstore bundle stored at path.
+func (c *Client) UploadAttestationFile(ctx context.Context, owner, repo, path string) (int64, error) {
+	bundle, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	id, err := c.UploadAttestation(ctx, owner, repo, bundle)
+	if err != nil {
+		return 0, fmt.Errorf("%w: attestation %s: %w", Error, path, err)
+	}
+	return id, nil
+}
+
+// ListAttestations returns attestations of artifact with subject digest
+// in form "sha256:<hex>".
+func (c *Client) ListAttestations(ctx context.Context, owner, repo, digest string) ([]Attestation, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, repoPath(owner, repo, "attestations", url.PathEscape(digest)), nil)
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		Attestations []Attestation `json:"attestations"`
+	}
+	if _, err := c.Do(req, &out); err != nil {
+		return nil, err
+	}
+	return out.Attestations, nil
+}
+
+// Attested reports whether every artifact the Sigstore bundle attests
+// already has attestation of the same predicate type in repository, so
+// publishing again does not store duplicate attestations. Bundles without
+// recognized subject are never reported as attested.
+func (c *Client) Attested(ctx context.Context, owner, repo string, bundle json.RawMessage) (bool, error) {
+	digests, predicateType := bundleSubjects(bundle)
+	if len(digests) == 0 {
+		return false, nil
+	}
+	for _, digest := range digests {
+		existing, err := c.ListAttestations(ctx, owner, repo, digest)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return false, err
+		}
+		found := false
+		for _, a := range existing {
+			if _, pt := bundleSubjects(a.Bundle); pt == predicateType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// bundleSubjects returns subject digests in form "sha256:<hex>" and
+// predicate type of in-toto statement of DSSE bundle. Message signature
+// bundles have single subject and empty predicate type.
+func bundleSubjects(bundle json.RawMessage) (digests []string, predicateType string) {
+	var b struct {
+		DSSEEnvelope *struct {
+			Payload []byte `json:"payload"`
+		} `json:"dsseEnvelope"`
+		MessageSignature *struct {
+			MessageDigest struct {
+				Algorithm string `json:"algorithm"`
+				Digest    []byte `json:"digest"`
+			} `json:"messageDigest"`
+		} `json:"messageSignature"`
+	}
+	if err := json.Unmarshal(bundle, &b); err != nil {
+		return nil, ""
+	}
+	switch {
+	case b.DSSEEnvelope != nil:
+		var statement struct {
+			PredicateType string `json:"predicateType"`
+			Subject       []struct {
+				Digest map[string]string `json:"digest"`
+			} `json:"subject"`
+		}
+		if err := json.Unmarshal(b.DSSEEnvelope.Payload, &statement); err != nil {
+			return nil, ""
+		}
+		for _, s := range statement.Subject {
+			if d := s.Digest["sha256"]; d != "" {
+				digests = append(digests, "sha256:"+d)
+			}
+		}
+		return digests, statement.PredicateType
+	case b.MessageSignature != nil && b.MessageSignature.MessageDigest.Algorithm == "SHA2_256":
+		return []string{"sha256:" + hex.EncodeToString(b.MessageSignature.MessageDigest.Digest)}, ""
+	}
+	return nil, ""
+}
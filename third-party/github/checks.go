@@ -0,0 +1,400 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrChecksFailed  = fmt.Errorf("%w: required checks failed", Error)
+	ErrChecksPending = fmt.Errorf("%w: required checks pending", Error)
+)
+
+// Check states reported by ChecksReport.
+const (
+	CheckSuccess = "success"
+	CheckPending = "pending"
+	CheckFailure = "failure"
+	CheckMissing = "missing"
+)
+
+// CommitStatus is a commit status reported by external services.
+type CommitStatus struct {
+	Context     string `json:"context"`
+	State       string `json:"state"`
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url"`
+}
+
+// CombinedStatus is the combined commit status of a ref.
+type CombinedStatus struct {
+	State    string         `json:"state"`
+	SHA      string         `json:"sha"`
+	Statuses []CommitStatus `json:"statuses"`
+}
+
+// CheckRun is a GitHub check run.
+type CheckRun struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	HeadSHA     string    `json:"head_sha"`
+	Status      string    `json:"status"`
+	Conclusion  string    `json:"conclusion"`
+	HTMLURL     string    `json:"html_url"`
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// GetCombinedStatus returns combined commit status of ref with statuses
+// of all pages.
+func (c *Client) GetCombinedStatus(ctx context.Context, owner, repo, ref string) (*CombinedStatus, error) {
+	var status *CombinedStatus
+	err := eachPage(ctx, c, repoPath(owner, repo, "commits", url.PathEscape(ref), "status")+"?per_page=100", func(page CombinedStatus) bool {
+		if status == nil {
+			status = &page
+		} else {
+			status.Statuses = append(status.Statuses, page.Statuses...)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// ListCheckRuns returns check runs of ref.
+func (c *Client) ListCheckRuns(ctx context.Context, owner, repo, ref string) ([]CheckRun, error) {
+	type page struct {
+		CheckRuns []CheckRun `json:"check_runs"`
+	}
+	var runs []CheckRun
+	err := eachPage(ctx, c, repoPath(owner, repo, "commits", url.PathEscape(ref), "check-runs")+"?per_page=100", func(p page) bool {
+		runs = append(runs, p.CheckRuns...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// RequiredStatusChecks returns names of status checks required by
+// protection of branch. Unprotected branch has no required checks.
+func (c *Client) RequiredStatusChecks(ctx context.Context, owner, repo, branch string) ([]string, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, repoPath(owner, repo, "branches", url.PathEscape(branch), "protection", "required_status_checks"), nil)
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		Contexts []string `json:"contexts"`
+		Checks   []struct {
+			Context string `json:"context"`
+		} `json:"checks"`
+	}
+	if _, err := c.Do(req, &out); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var required []string
+	for _, name := range out.Contexts {
+		if !seen[name] {
+			seen[name] = true
+			required = append(required, name)
+		}
+	}
+	for _, check := range out.Checks {
+		if !seen[check.Context] {
+			seen[check.Context] = true
+			required = append(required, check.Context)
+		}
+	}
+	return required, nil
+}
+
+// ChecksReport is state of required checks of a ref.
+type ChecksReport struct {
+	Ref string
+	// Checks maps required check name to one of the Check* states.
+	Checks map[string]string
+}
+
+// Err returns ErrChecksFailed when any required check failed,
+// ErrChecksPending when any is still pending or missing and nil
+// when all required checks succeeded.
+func (r *ChecksReport) Err() error {
+	var failed, pending []string
+	for name, state := range r.Checks {
+		switch state {
+		case CheckSuccess:
+		case CheckFailure:
+			failed = append(failed, name)
+		default:
+			pending = append(pending, name)
+		}
+	}
+	sort.Strings(failed)
+	sort.Strings(pending)
+	if len(failed) > 0 {
+		return fmt.Errorf("%w: %s", ErrChecksFailed, strings.Join(failed, ", "))
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %s", ErrChecksPending, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// RequiredChecks compares check runs and commit statuses of ref against
+// the required status checks of branch protection.
+func (c *Client) RequiredChecks(ctx context.Context, owner, repo, ref, branch string) (*ChecksReport, error) {
+	required, err := c.RequiredStatusChecks(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	report := &ChecksReport{Ref: ref, Checks: make(map[string]string, len(required))}
+	if len(required) == 0 {
+		return report, nil
+	}
+
+	observed := make(map[string]string)
+	status, err := c.GetCombinedStatus(ctx, owner, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range status.Statuses {
+		switch s.State {
+		case "success":
+			observed[s.Context] = CheckSuccess
+		case "pending":
+			observed[s.Context] = CheckPending
+		default:
+			observed[s.Context] = CheckFailure
+		}
+	}
+	runs, err := c.ListCheckRuns(ctx, owner, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range runs {
+		switch {
+		case run.Status != "completed":
+			observed[run.Name] = CheckPending
+		case run.Conclusion == "success", run.Conclusion == "neutral", run.Conclusion == "skipped":
+			observed[run.Name] = CheckSuccess
+		default:
+			observed[run.Name] = CheckFailure
+		}
+	}
+
+	for _, name := range required {
+		state, ok := observed[name]
+		if !ok {
+			state = CheckMissing
+		}
+		report.Checks[name] = state
+	}
+	return report, nil
+}
+
+// ChecksGate blocks release until required checks of the release branch
+// are green.
+type ChecksGate struct {
+	client *Client
+	owner  string
+	repo   string
+	branch string
+	// Poll is interval between checks while waiting, zero disables waiting.
+	Poll time.Duration
+}
+
+// NewChecksGate returns gate for the protected branch of owner/repo.
+func NewChecksGate(client *Client, owner, repo, branch string) *ChecksGate {
+	return &ChecksGate{
+		client: client,
+		owner:  owner,
+		repo:   repo,
+		branch: branch,
+		Poll:   30 * time.Second,
+	}
+}
+
+// Check returns nil when all required checks of ref succeeded. While checks
+// are pending it polls until they complete or ctx is done.
+func (g *ChecksGate) Check(ctx context.Context, ref string) error {
+	for {
+		report, err := g.client.RequiredChecks(ctx, g.owner, g.repo, ref, g.branch)
+		if err != nil {
+			return err
+		}
+		err = report.Err()
+		if !errors.Is(err, ErrChecksPending) || g.Poll <= 0 {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", err, ctx.Err())
+		case <-time.After(g.Poll):
+		}
+	}
+}
+
+// CheckRunOutput is summary of a check run shown on the checks tab.
+type CheckRunOutput struct {
+	Title   string `json:"title"`
+	Summary string `json:"summary"`
+	Text    string `json:"text,omitempty"`
+}
+
+// CheckRunParams are the parameters to create or update a check run.
+type CheckRunParams struct {
+	Name        string          `json:"name,omitempty"`
+	HeadSHA     string          `json:"head_sha,omitempty"`
+	Status      string          `json:"status,omitempty"`
+	Conclusion  string          `json:"conclusion,omitempty"`
+	DetailsURL  string          `json:"details_url,omitempty"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+	Output      *CheckRunOutput `json:"output,omitempty"`
+}
+
+// CreateCheckRun creates a check run, requires GitHub App token
+// with checks write permission e.g. GITHUB_TOKEN of Actions workflow.
+func (c *Client) CreateCheckRun(ctx context.Context, owner, repo string, params CheckRunParams) (*CheckRun, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, repoPath(owner, repo, "check-runs"), params)
+	if err != nil {
+		return nil, err
+	}
+	run := &CheckRun{}
+	if _, err := c.Do(req, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// UpdateCheckRun updates check run id.
+func (c *Client) UpdateCheckRun(ctx context.Context, owner, repo string, id int64, params CheckRunParams) (*CheckRun, error) {
+	params.HeadSHA = ""
+	req, err := c.NewRequest(ctx, http.MethodPatch, repoPath(owner, repo, "check-runs", fmt.Sprint(id)), params)
+	if err != nil {
+		return nil, err
+	}
+	run := &CheckRun{}
+	if _, err := c.Do(req, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// Stages reports release stages (lint, test, tag, publish) as check runs
+// of a commit. Stages are no-op when disabled or when token lacks the
+// permission to create check runs, reporting is never fatal for release.
+type Stages struct {
+	client  *Client
+	owner   string
+	repo    string
+	sha     string
+	prefix  string
+	mu      sync.Mutex
+	enabled bool
+	runs    map[string]int64
+}
+
+// NewStages returns stages reporting check runs named "<prefix> / <stage>"
+// on commit sha. Empty prefix defaults to "release".
+func NewStages(client *Client, owner, repo, sha, prefix string, enabled bool) *Stages {
+	if prefix == "" {
+		prefix = "release"
+	}
+	return &Stages{
+		client:  client,
+		owner:   owner,
+		repo:    repo,
+		sha:     sha,
+		prefix:  prefix,
+		enabled: enabled,
+		runs:    make(map[string]int64),
+	}
+}
+
+// Start marks stage as in progress.
+func (s *Stages) Start(ctx context.Context, stage, summary string) error {
+	now := time.Now()
+	return s.report(ctx, stage, CheckRunParams{
+		Status:    "in_progress",
+		StartedAt: &now,
+		Output:    &CheckRunOutput{Title: stage, Summary: summary},
+	})
+}
+
+// Progress updates summary of running stage.
+func (s *Stages) Progress(ctx context.Context, stage, summary string) error {
+	return s.report(ctx, stage, CheckRunParams{
+		Status: "in_progress",
+		Output: &CheckRunOutput{Title: stage, Summary: summary},
+	})
+}
+
+// Finish completes stage, failed when err is not nil.
+func (s *Stages) Finish(ctx context.Context, stage string, err error, summary string) error {
+	now := time.Now()
+	params := CheckRunParams{
+		Status:      "completed",
+		Conclusion:  "success",
+		CompletedAt: &now,
+		Output:      &CheckRunOutput{Title: stage + " succeeded", Summary: summary},
+	}
+	if err != nil {
+		params.Conclusion = "failure"
+		params.Output.Title = stage + " failed"
+		params.Output.Text = err.Error()
+	}
+	return s.report(ctx, stage, params)
+}
+
+func (s *Stages) report(ctx context.Context, stage string, params CheckRunParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.enabled {
+		return nil
+	}
+	if params.Output != nil && params.Output.Summary == "" {
+		params.Output.Summary = params.Output.Title
+	}
+
+	var (
+		run *CheckRun
+		err error
+	)
+	if id, ok := s.runs[stage]; ok {
+		run, err = s.client.UpdateCheckRun(ctx, s.owner, s.repo, id, params)
+	} else {
+		params.Name = s.prefix + " / " + stage
+		params.HeadSHA = s.sha
+		run, err = s.client.CreateCheckRun(ctx, s.owner, s.repo, params)
+	}
+
+	var errResp *ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response.StatusCode == http.StatusForbidden && !errors.Is(err, ErrRateLimit) {
+		s.enabled = false
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s.runs[stage] = run.ID
+	return nil
+}
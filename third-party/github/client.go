@@ -0,0 +1,480 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBaseURL   = "https://api.github.com/"
+	defaultUserAgent = "happy-sdk-github-addon"
+	defaultRetries   = 3
+	defaultRetryWait = time.Second
+	apiVersion       = "2022-11-28"
+)
+
+var (
+	Error        = errors.New("github")
+	ErrNoToken   = fmt.Errorf("%w: no token available", Error)
+	ErrNotFound  = fmt.Errorf("%w: not found", Error)
+	ErrReadBody  = fmt.Errorf("%w: failed to read response body", Error)
+	ErrRateLimit = fmt.Errorf("%w: rate limit exceeded", Error)
+)
+
+// ErrorResponse is returned for any API response with a non 2xx status code.
+type ErrorResponse struct {
+	Response         *http.Response `json:"-"`
+	Message          string         `json:"message"`
+	DocumentationURL string         `json:"documentation_url,omitempty"`
+	Errors           []struct {
+		Resource string `json:"resource"`
+		Field    string `json:"field"`
+		Code     string `json:"code"`
+		Message  string `json:"message,omitempty"`
+	} `json:"errors,omitempty"`
+
+	rateLimited bool
+}
+
+func (e *ErrorResponse) Error() string {
+	msg := fmt.Sprintf("%s %s: %d %s",
+		e.Response.Request.Method, e.Response.Request.URL.Path,
+		e.Response.StatusCode, e.Message)
+	for _, ee := range e.Errors {
+		if ee.Message != "" {
+			msg += "; " + ee.Message
+			continue
+		}
+		msg += fmt.Sprintf("; %s.%s %s", ee.Resource, ee.Field, ee.Code)
+	}
+	return msg
+}
+
+func (e *ErrorResponse) Unwrap() error {
+	if e.Response.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if e.rateLimited {
+		return ErrRateLimit
+	}
+	return Error
+}
+
+// ClientOption configures a Client.
+type ClientOption func(c *Client) error
+
+// WithBaseURL sets the API base url, e.g. for GitHub Enterprise
+// "https://github.example.com/api/v3/".
+func WithBaseURL(u string) ClientOption {
+	return func(c *Client) error {
+		if !strings.HasSuffix(u, "/") {
+			u += "/"
+		}
+		base, err := url.Parse(u)
+		if err != nil {
+			return fmt.Errorf("%w: invalid base url: %s", Error, err)
+		}
+		c.baseURL = base
+		return nil
+	}
+}
+
+// WithHTTPClient sets the underlying http client.
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *Client) error {
+		c.http = hc
+		return nil
+	}
+}
+
+// WithRetries sets how many times a failed idempotent request is retried
+// and the initial wait between attempts, doubled on every attempt.
+func WithRetries(n int, wait time.Duration) ClientOption {
+	return func(c *Client) error {
+		c.retries = n
+		c.retryWait = wait
+		return nil
+	}
+}
+
+// WithTokenSource authenticates requests with tokens from ts,
+// replacing the static token passed to NewClient.
+func WithTokenSource(ts TokenSource) ClientOption {
+	return func(c *Client) error {
+		c.tokens = ts
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) ClientOption {
+	return func(c *Client) error {
+		c.userAgent = ua
+		return nil
+	}
+}
+
+// Client is an authenticated GitHub REST API client.
+type Client struct {
+	baseURL   *url.URL
+	http      *http.Client
+	tokens    TokenSource
+	userAgent string
+	retries   int
+	retryWait time.Duration
+
+	rateLimitWait time.Duration
+	cache         *responseCache
+	mu            sync.Mutex
+	rateLimit     RateLimit
+}
+
+// NewClient returns a new client authenticating with token.
+// Empty token creates an unauthenticated client.
+func NewClient(token string, opts ...ClientOption) (*Client, error) {
+	base, _ := url.Parse(defaultBaseURL)
+	c := &Client{
+		baseURL:   base,
+		http:      &http.Client{Timeout: time.Minute},
+		tokens:    StaticToken(token),
+		userAgent: defaultUserAgent,
+		retries:   defaultRetries,
+		retryWait: defaultRetryWait,
+
+		rateLimitWait: defaultRateLimitWait,
+		cache:         newResponseCache(defaultCacheSize),
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// NewRequest creates an API request. Relative path is resolved against
+// the base url, body if not nil is JSON encoded.
+func (c *Client) NewRequest(ctx context.Context, method, path string, body any) (*http.Request, error) {
+	u, err := c.baseURL.Parse(strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid path %q: %s", Error, path, err)
+	}
+
+	var r io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to encode request body: %s", Error, err)
+		}
+		r = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), r)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", apiVersion)
+	req.Header.Set("User-Agent", c.userAgent)
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req, nil
+}
+
+// Do sends the request and decodes JSON response into v when v is not nil.
+// If v implements io.Writer the raw response body is copied into it.
+// Idempotent requests are retried on network errors and 5xx responses,
+// requests rejected by rate limit are retried once the limit resets.
+// GET responses of JSON API carrying ETag are cached and revalidated with
+// conditional requests, responses streamed into io.Writer are never cached.
+func (c *Client) Do(req *http.Request, v any) (*http.Response, error) {
+	_, stream := v.(io.Writer)
+	resp, err := c.send(req, !stream)
+	if err != nil {
+		return resp, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return resp, err
+	}
+
+	switch v := v.(type) {
+	case nil:
+	case io.Writer:
+		if _, err := io.Copy(v, resp.Body); err != nil {
+			return resp, fmt.Errorf("%w: %s", ErrReadBody, err)
+		}
+	default:
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+			return resp, fmt.Errorf("%w: %s", ErrReadBody, err)
+		}
+	}
+	return resp, nil
+}
+
+func (c *Client) send(req *http.Request, cache bool) (*http.Response, error) {
+	var (
+		key    string
+		cached *cachedResponse
+	)
+	if cache && c.cache != nil && req.Method == http.MethodGet && isJSON(req.Header.Get("Accept")) {
+		key = cacheKey(req)
+		if cached = c.cache.get(key); cached != nil {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+	}
+
+	attempts := 1
+	if isIdempotent(req.Method) && (req.Body == nil || req.GetBody != nil) {
+		attempts += c.retries
+	}
+
+	wait := c.retryWait
+	rateLimitRetries := 0
+	for attempt := 1; ; {
+		resp, err := c.http.Do(req)
+		if err == nil {
+			c.trackRateLimit(resp)
+		}
+
+		var delay time.Duration
+		switch {
+		case err != nil || resp.StatusCode >= http.StatusInternalServerError:
+			if attempt >= attempts {
+				return resp, err
+			}
+			attempt++
+			delay = wait
+			wait *= 2
+		case resp.StatusCode == http.StatusNotModified && cached != nil:
+			resp.Body.Close()
+			return cached.response(req), nil
+		default:
+			d, limited := rateLimited(resp, time.Now())
+			if !limited || d > c.rateLimitWait || rateLimitRetries >= maxRateLimitRetries ||
+				(req.Body != nil && req.GetBody == nil) {
+				if key != "" {
+					if err := c.cache.store(key, resp); err != nil {
+						return resp, fmt.Errorf("%w: %s", ErrReadBody, err)
+					}
+				}
+				return resp, nil
+			}
+			rateLimitRetries++
+			delay = d
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// listPages fetches list at path page by page following the Link header
+// and calls fn with items of every page until fn returns false.
+func listPages[T any](ctx context.Context, c *Client, path string, fn func(items []T) bool) error {
+	return eachPage(ctx, c, path, fn)
+}
+
+// eachPage decodes every page of list at path into P, for lists wrapped
+// in an object, and calls fn with it until fn returns false.
+func eachPage[P any](ctx context.Context, c *Client, path string, fn func(page P) bool) error {
+	for path != "" {
+		req, err := c.NewRequest(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return err
+		}
+		var page P
+		resp, err := c.Do(req, &page)
+		if err != nil {
+			return err
+		}
+		if !fn(page) {
+			return nil
+		}
+		path = nextPage(resp)
+	}
+	return nil
+}
+
+// listAll returns items of all pages of list at path.
+func listAll[T any](ctx context.Context, c *Client, path string) ([]T, error) {
+	var all []T
+	err := listPages(ctx, c, path, func(items []T) bool {
+		all = append(all, items...)
+		return true
+	})
+	return all, err
+}
+
+// nextPage returns URL of the next page from Link header, empty when
+// response is the last page.
+func nextPage(resp *http.Response) string {
+	for _, link := range strings.Split(resp.Header.Get("Link"), ",") {
+		target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+		if !ok || !strings.Contains(params, `rel="next"`) {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(target), "<>")
+	}
+	return ""
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	errResp := &ErrorResponse{Response: resp}
+	_, errResp.rateLimited = rateLimited(resp, time.Now())
+	data, err := io.ReadAll(resp.Body)
+	if err == nil && len(data) > 0 {
+		_ = json.Unmarshal(data, errResp)
+	}
+	if errResp.Message == "" {
+		errResp.Message = http.StatusText(resp.StatusCode)
+	}
+	return errResp
+}
+
+// TokenSource provides tokens to authenticate requests with.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken is a TokenSource always returning the same token.
+type StaticToken string
+
+func (t StaticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// ResolveToken returns the token to authenticate with. The configured
+// token takes precedence, then GITHUB_TOKEN and GH_TOKEN environment
+// variables and finally the token of an authenticated gh CLI.
+func ResolveToken(ctx context.Context, configured string) (string, error) {
+	if configured != "" {
+		return configured, nil
+	}
+	for _, key := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+		if token := os.Getenv(key); token != "" {
+			return token, nil
+		}
+	}
+	if _, err := exec.LookPath("gh"); err != nil {
+		return "", ErrNoToken
+	}
+	out, err := exec.CommandContext(ctx, "gh", "auth", "token").Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: gh auth token: %s", ErrNoToken, err)
+	}
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", ErrNoToken
+	}
+	return token, nil
+}
+
+// User is a GitHub user or organization account.
+type User struct {
+	ID      int64  `json:"id"`
+	Login   string `json:"login"`
+	Name    string `json:"name,omitempty"`
+	Email   string `json:"email,omitempty"`
+	Type    string `json:"type"`
+	HTMLURL string `json:"html_url"`
+}
+
+// Repository is a GitHub repository.
+type Repository struct {
+	ID            int64     `json:"id"`
+	NodeID        string    `json:"node_id"`
+	Name          string    `json:"name"`
+	FullName      string    `json:"full_name"`
+	Owner         User      `json:"owner"`
+	Private       bool      `json:"private"`
+	Archived      bool      `json:"archived"`
+	DefaultBranch string    `json:"default_branch"`
+	HTMLURL       string    `json:"html_url"`
+	CloneURL      string    `json:"clone_url"`
+	PushedAt      time.Time `json:"pushed_at"`
+}
+
+// CurrentUser returns the authenticated user.
+func (c *Client) CurrentUser(ctx context.Context) (*User, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, "user", nil)
+	if err != nil {
+		return nil, err
+	}
+	user := &User{}
+	if _, err := c.Do(req, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetRepository returns the repository owner/repo.
+func (c *Client) GetRepository(ctx context.Context, owner, repo string) (*Repository, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, repoPath(owner, repo), nil)
+	if err != nil {
+		return nil, err
+	}
+	r := &Repository{}
+	if _, err := c.Do(req, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func repoPath(owner, repo string, elem ...string) string {
+	p := "repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo)
+	for _, e := range elem {
+		p += "/" + e
+	}
+	return p
+}
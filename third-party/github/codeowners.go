@@ -0,0 +1,179 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package github
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// codeownersLocations are searched in order, as GitHub does.
+var codeownersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+type codeownersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// Codeowners are parsed CODEOWNERS rules.
+type Codeowners struct {
+	rules []codeownersRule
+}
+
+// LoadCodeowners reads CODEOWNERS file of repository at root. Returns
+// empty rules when repository has no CODEOWNERS file.
+func LoadCodeowners(root string) (*Codeowners, error) {
+	for _, loc := range codeownersLocations {
+		f, err := os.Open(filepath.Join(root, loc))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return ParseCodeowners(f)
+	}
+	return &Codeowners{}, nil
+}
+
+// ParseCodeowners parses CODEOWNERS rules from r.
+func ParseCodeowners(r io.Reader) (*Codeowners, error) {
+	co := &Codeowners{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if i := commentIndex(line); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		re, err := codeownersPattern(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: CODEOWNERS line %d: %s", Error, n, err)
+		}
+		co.rules = append(co.rules, codeownersRule{pattern: re, owners: fields[1:]})
+	}
+	return co, scanner.Err()
+}
+
+// Owners returns owners of path relative to repository root. Last
+// matching rule takes precedence.
+func (co *Codeowners) Owners(path string) []string {
+	path = strings.TrimPrefix(filepath.ToSlash(path), "/")
+	for i := len(co.rules) - 1; i >= 0; i-- {
+		if co.rules[i].pattern.MatchString(path) {
+			return co.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// Reviewers returns users and team slugs owning any of paths.
+// Owners given by email are skipped as reviews can't be requested from them.
+func (co *Codeowners) Reviewers(paths []string) (users, teams []string) {
+	seenUsers, seenTeams := make(map[string]bool), make(map[string]bool)
+	for _, p := range paths {
+		for _, owner := range co.Owners(p) {
+			name, ok := strings.CutPrefix(owner, "@")
+			if !ok {
+				continue
+			}
+			if _, team, isTeam := strings.Cut(name, "/"); isTeam {
+				if !seenTeams[team] {
+					seenTeams[team] = true
+					teams = append(teams, team)
+				}
+				continue
+			}
+			if !seenUsers[name] {
+				seenUsers[name] = true
+				users = append(users, name)
+			}
+		}
+	}
+	sort.Strings(users)
+	sort.Strings(teams)
+	return users, teams
+}
+
+// commentIndex returns index of unescaped "#" starting a comment.
+func commentIndex(line string) int {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '#':
+			return i
+		}
+	}
+	return -1
+}
+
+// codeownersPattern converts gitignore style pattern to regexp.
+func codeownersPattern(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.HasPrefix(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	// pattern with slash in the middle is relative to the root
+	if strings.Contains(pattern, "/") {
+		anchored = true
+	}
+	// trailing "/*" owns only direct children, unlike recursive "/**"
+	children := strings.HasSuffix(pattern, "/*") && !strings.HasSuffix(pattern, "/**")
+	if children {
+		pattern = strings.TrimSuffix(pattern, "/*")
+	}
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch ch := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "/**"):
+			b.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case ch == '*':
+			b.WriteString("[^/]*")
+		case ch == '?':
+			b.WriteString("[^/]")
+		case ch == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	switch {
+	case children:
+		b.WriteString("/[^/]+$")
+	case dirOnly:
+		b.WriteString("/.*$")
+	default:
+		// matching directory matches everything below it
+		b.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,278 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package github
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/happy-sdk/happy"
+	"github.com/happy-sdk/happy/pkg/varflag"
+)
+
+// command returns the github command group provided when
+// command.enabled setting is set.
+func (gh *Github) command() *happy.Command {
+	cmd := happy.NewCommand("github",
+		happy.Option("usage", "GitHub repository operations"),
+		happy.Option("category", "github"),
+	)
+	cmd.Do(func(sess *happy.Session, args happy.Args) error {
+		return gh.status(sess)
+	})
+
+	cmd.AddSubCommand(gh.releaseCommand())
+	cmd.AddSubCommand(gh.issueCommand())
+	cmd.AddSubCommand(gh.prCommand())
+
+	status := happy.NewCommand("status",
+		happy.Option("usage", "show repository, authenticated user, rate limit and commit status"),
+	)
+	status.AddFlag(varflag.StringFunc("ref", "HEAD", "commit status of git ref"))
+	status.Do(func(sess *happy.Session, args happy.Args) error {
+		return gh.status(sess, args.Flag("ref").String())
+	})
+	cmd.AddSubCommand(status)
+	return cmd
+}
+
+func (gh *Github) releaseCommand() *happy.Command {
+	release := happy.NewCommand("release",
+		happy.Option("usage", "manage releases"),
+	)
+
+	list := happy.NewCommand("list",
+		happy.Option("usage", "list releases"),
+	)
+	list.AddFlag(varflag.UintFunc("limit", 30, "maximum number of releases"))
+	list.Do(func(sess *happy.Session, args happy.Args) error {
+		client, err := gh.Client(sess)
+		if err != nil {
+			return err
+		}
+		releases, err := client.ListReleases(sess, gh.Owner(), gh.Repo(), int(args.Flag("limit").Var().Uint()))
+		if err != nil {
+			return err
+		}
+		for _, rel := range releases {
+			state := "published"
+			switch {
+			case rel.Draft:
+				state = "draft"
+			case rel.Prerelease:
+				state = "prerelease"
+			}
+			fmt.Printf("%-30s %-10s %s\n", rel.TagName, state, rel.HTMLURL)
+		}
+		return nil
+	})
+	release.AddSubCommand(list)
+
+	create := happy.NewCommand("create",
+		happy.Option("usage", "create release of pushed tag: create <tag> [assets...]"),
+		happy.Option("argn.min", 1),
+	)
+	create.AddFlag(varflag.StringFunc("name", "", "release name, defaults to tag"))
+	create.AddFlag(varflag.StringFunc("notes", "", "release notes"))
+	create.AddFlag(varflag.StringFunc("previous-tag", "", "previous release tag used for generated notes"))
+	create.AddFlag(varflag.BoolFunc("draft", false, "create draft release"))
+	create.AddFlag(varflag.BoolFunc("prerelease", false, "mark release as prerelease"))
+	create.Do(func(sess *happy.Session, args happy.Args) error {
+		pub, err := gh.Publisher(sess)
+		if err != nil {
+			return err
+		}
+		req := PublishRequest{
+			Tag:         args.Arg(0).String(),
+			Name:        args.Flag("name").String(),
+			Notes:       args.Flag("notes").String(),
+			PreviousTag: args.Flag("previous-tag").String(),
+		}
+		for _, a := range args.Args()[1:] {
+			req.Assets = append(req.Assets, a.String())
+		}
+		if f := args.Flag("draft"); f.Present() {
+			draft := f.Var().Bool()
+			req.Draft = &draft
+		}
+		if f := args.Flag("prerelease"); f.Present() {
+			prerelease := f.Var().Bool()
+			req.Prerelease = &prerelease
+		}
+		rels, err := pub.Publish(sess, req)
+		if err != nil {
+			return err
+		}
+		for _, rel := range rels {
+			fmt.Println(rel.HTMLURL)
+		}
+		return nil
+	})
+	release.AddSubCommand(create)
+	return release
+}
+
+func (gh *Github) issueCommand() *happy.Command {
+	issue := happy.NewCommand("issue",
+		happy.Option("usage", "manage issues"),
+	)
+
+	list := happy.NewCommand("list",
+		happy.Option("usage", "list issues"),
+	)
+	list.AddFlag(varflag.StringFunc("state", "open", "issue state: open, closed or all"))
+	list.AddFlag(varflag.StringFunc("label", "", "comma separated labels"))
+	list.AddFlag(varflag.StringFunc("assignee", "", "assignee login"))
+	list.AddFlag(varflag.UintFunc("limit", 30, "maximum number of issues"))
+	list.Do(func(sess *happy.Session, args happy.Args) error {
+		client, err := gh.Client(sess)
+		if err != nil {
+			return err
+		}
+		issues, err := client.ListIssues(sess, gh.Owner(), gh.Repo(), IssueListOptions{
+			State:    args.Flag("state").String(),
+			Labels:   splitList(args.Flag("label").String()),
+			Assignee: args.Flag("assignee").String(),
+			Limit:    int(args.Flag("limit").Var().Uint()),
+		})
+		if err != nil {
+			return err
+		}
+		for _, is := range issues {
+			fmt.Printf("#%-6d %-7s %s\n", is.Number, is.State, is.Title)
+		}
+		return nil
+	})
+	issue.AddSubCommand(list)
+
+	create := happy.NewCommand("create",
+		happy.Option("usage", "create issue: create <title>"),
+		happy.Option("argn.min", 1),
+	)
+	create.AddFlag(varflag.StringFunc("body", "", "issue body"))
+	create.AddFlag(varflag.StringFunc("label", "", "comma separated labels"))
+	create.AddFlag(varflag.StringFunc("assignee", "", "comma separated assignee logins"))
+	create.Do(func(sess *happy.Session, args happy.Args) error {
+		client, err := gh.Client(sess)
+		if err != nil {
+			return err
+		}
+		is, err := client.CreateIssue(sess, gh.Owner(), gh.Repo(), IssueParams{
+			Title:     args.Arg(0).String(),
+			Body:      args.Flag("body").String(),
+			Labels:    splitList(args.Flag("label").String()),
+			Assignees: splitList(args.Flag("assignee").String()),
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Println(is.HTMLURL)
+		return nil
+	})
+	issue.AddSubCommand(create)
+	return issue
+}
+
+func (gh *Github) prCommand() *happy.Command {
+	pr := happy.NewCommand("pr",
+		happy.Option("usage", "manage pull requests"),
+	)
+
+	create := happy.NewCommand("create",
+		happy.Option("usage", "open pull request or update existing one: create <title>"),
+		happy.Option("argn.min", 1),
+	)
+	create.AddFlag(varflag.StringFunc("head", "", "branch with changes, defaults to current branch"))
+	create.AddFlag(varflag.StringFunc("base", "", "branch to merge into, defaults to default branch"))
+	create.AddFlag(varflag.StringFunc("body", "", "pull request body"))
+	create.AddFlag(varflag.StringFunc("label", "", "comma separated labels"))
+	create.AddFlag(varflag.StringFunc("reviewer", "", "comma separated reviewer logins"))
+	create.AddFlag(varflag.BoolFunc("draft", false, "open as draft"))
+	create.Do(func(sess *happy.Session, args happy.Args) error {
+		head := args.Flag("head").String()
+		if head == "" {
+			out, err := exec.CommandContext(sess, "git", "branch", "--show-current").Output()
+			if err != nil {
+				return fmt.Errorf("%w: git branch --show-current: %s", Error, err)
+			}
+			head = strings.TrimSpace(string(out))
+		}
+		base := args.Flag("base").String()
+		if base == "" {
+			client, err := gh.Client(sess)
+			if err != nil {
+				return err
+			}
+			repo, err := client.GetRepository(sess, gh.Owner(), gh.Repo())
+			if err != nil {
+				return err
+			}
+			base = repo.DefaultBranch
+		}
+		// touched paths select code owners requested to review
+		var touched []string
+		if out, err := exec.CommandContext(sess, "git", "diff", "--name-only", base+"..."+head).Output(); err == nil {
+			touched = strings.Fields(string(out))
+		}
+		p, err := gh.OpenPullRequest(sess, PullRequestSpec{
+			PullRequestParams: PullRequestParams{
+				Title: args.Arg(0).String(),
+				Body:  args.Flag("body").String(),
+				Head:  head,
+				Base:  base,
+				Draft: args.Flag("draft").Var().Bool(),
+			},
+			Labels:    splitList(args.Flag("label").String()),
+			Reviewers: splitList(args.Flag("reviewer").String()),
+		}, touched)
+		if err != nil {
+			return err
+		}
+		fmt.Println(p.HTMLURL)
+		return nil
+	})
+	pr.AddSubCommand(create)
+	return pr
+}
+
+// status prints repository, authenticated user, rate limit and combined
+// commit status of optional git ref.
+func (gh *Github) status(sess *happy.Session, ref ...string) error {
+	client, err := gh.Client(sess)
+	if err != nil {
+		return err
+	}
+	repo, err := client.GetRepository(sess, gh.Owner(), gh.Repo())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("repository:  %s (%s)\n", repo.FullName, repo.HTMLURL)
+	if me, err := client.CurrentUser(sess); err == nil {
+		fmt.Printf("user:        %s\n", me.Login)
+	}
+	if len(ref) > 0 && ref[0] != "" {
+		sha := ref[0]
+		if out, err := exec.CommandContext(sess, "git", "rev-parse", sha).Output(); err == nil {
+			sha = strings.TrimSpace(string(out))
+		}
+		if cs, err := client.GetCombinedStatus(sess, gh.Owner(), gh.Repo(), sha); err == nil {
+			fmt.Printf("status:      %s %s\n", cs.State, cs.SHA)
+		}
+	}
+	rl := client.RateLimit()
+	fmt.Printf("rate limit:  %d/%d, resets %s\n", rl.Remaining, rl.Limit, rl.Reset.Format("15:04:05"))
+	return nil
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -5,14 +5,38 @@
 package github
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
 	"github.com/happy-sdk/happy"
 	"github.com/happy-sdk/happy/sdk/settings"
 )
 
 type Settings struct {
-	Owner          settings.String `key:"owner" default:"octocat" mutation:"once"`
-	Repo           settings.String `key:"repo" default:"hello-worId" mutation:"once"`
-	CommandEnabled settings.Bool   `key:"command.enabled" default:"false" mutation:"once"`
+	Owner                     settings.String `key:"owner" mutation:"once"`
+	Repo                      settings.String `key:"repo" mutation:"once"`
+	Token                     settings.String `key:"token" mutation:"once"`
+	BaseURL                   settings.String `key:"base_url" default:"https://api.github.com/" mutation:"once"`
+	CommandEnabled            settings.Bool   `key:"command.enabled" default:"false" mutation:"once"`
+	ReleaseDraft              settings.Bool   `key:"release.draft" default:"false" mutation:"once"`
+	ReleasePrerelease         settings.String `key:"release.prerelease" default:"auto" mutation:"once"`
+	ReleaseNotes              settings.String `key:"release.notes" default:"changelog" mutation:"once"`
+	ReleaseMilestones         settings.Bool   `key:"release.milestones" default:"false" mutation:"once"`
+	ReleaseDiscussionCategory settings.String `key:"release.discussion_category" mutation:"once"`
+	LabelsFile                settings.String `key:"labels.file" default:".github/labels.json" mutation:"once"`
+	LabelsPrune               settings.Bool   `key:"labels.prune" default:"false" mutation:"once"`
+	ChecksEnabled             settings.Bool   `key:"checks.enabled" default:"false" mutation:"once"`
+	WebhookSecret             settings.String `key:"webhook.secret" mutation:"once"`
+	AlertsGate                settings.String `key:"alerts.gate" default:"off" mutation:"once"`
+	AlertsSeverity            settings.String `key:"alerts.severity" default:"critical" mutation:"once"`
+	AppID                     settings.Int    `key:"app.id" default:"0" mutation:"once"`
+	AppInstallationID         settings.Int    `key:"app.installation_id" default:"0" mutation:"once"`
+	AppPrivateKey             settings.String `key:"app.private_key" mutation:"once"`
 }
 
 func (s Settings) Blueprint() (*settings.Blueprint, error) {
@@ -24,10 +48,304 @@ func (s Settings) Blueprint() (*settings.Blueprint, error) {
 	return b, nil
 }
 
-type Github struct{}
+type labelSettings struct {
+	file  string
+	prune bool
+}
+
+type alertSettings struct {
+	mode     string
+	severity string
+}
+
+type appSettings struct {
+	id             int64
+	installationID int64
+	privateKey     string
+}
+
+// Github is the API provided by the github addon.
+type Github struct {
+	happy.API
+
+	mu         sync.Mutex
+	owner      string
+	repo       string
+	token      string
+	baseURL    string
+	draft      bool
+	prerelease string
+	notes      string
+	milestones bool
+	discussion string
+	labels     labelSettings
+	checks     bool
+	webhook    string
+	alerts     alertSettings
+	app        appSettings
+	client     *Client
+	// remoteErr is error of detecting repository from git remote,
+	// returned by API calls when owner or repo is not configured.
+	remoteErr error
+}
 
 func Addon(s Settings) *happy.Addon {
 	addon := happy.NewAddon("github", s)
 
+	api := &Github{}
+	addon.ProvidesAPI(api)
+
+	if s.CommandEnabled {
+		addon.ProvidesCommand(api.command())
+	}
+
+	addon.OnRegister(func(sess *happy.Session) error {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		api.owner = setting(sess, "owner")
+		api.repo = setting(sess, "repo")
+		api.token = setting(sess, "token")
+		api.baseURL = setting(sess, "base_url")
+		api.draft = setting(sess, "release.draft") == "true"
+		api.prerelease = setting(sess, "release.prerelease")
+		api.notes = setting(sess, "release.notes")
+		api.milestones = setting(sess, "release.milestones") == "true"
+		api.discussion = setting(sess, "release.discussion_category")
+		api.labels.file = setting(sess, "labels.file")
+		api.labels.prune = setting(sess, "labels.prune") == "true"
+		api.checks = setting(sess, "checks.enabled") == "true"
+		api.webhook = setting(sess, "webhook.secret")
+		api.alerts.mode = setting(sess, "alerts.gate")
+		api.alerts.severity = setting(sess, "alerts.severity")
+		api.app.id, _ = strconv.ParseInt(setting(sess, "app.id"), 10, 64)
+		api.app.installationID, _ = strconv.ParseInt(setting(sess, "app.installation_id"), 10, 64)
+		api.app.privateKey = setting(sess, "app.private_key")
+
+		// Owner and repo settings are only overrides of the repository
+		// detected from git remote of the working directory.
+		if api.owner != "" && api.repo != "" {
+			return nil
+		}
+		wd, err := os.Getwd()
+		if err != nil {
+			api.remoteErr = fmt.Errorf("%w: %s", ErrRemote, err)
+			return nil
+		}
+		remote, err := DetectRemote(sess, wd)
+		if err == nil {
+			err = checkRemoteHost(remote, api.baseURL)
+		}
+		if err != nil {
+			api.remoteErr = err
+			return nil
+		}
+		if api.owner == "" {
+			api.owner = remote.Owner
+		}
+		if api.repo == "" {
+			api.repo = remote.Repo
+		}
+		return nil
+	})
+
 	return addon
 }
+
+// Owner returns the configured repository owner.
+func (gh *Github) Owner() string {
+	gh.mu.Lock()
+	defer gh.mu.Unlock()
+	return gh.owner
+}
+
+// Repo returns the configured repository name.
+func (gh *Github) Repo() string {
+	gh.mu.Lock()
+	defer gh.mu.Unlock()
+	return gh.repo
+}
+
+// Client returns the authenticated API client, creating it on first use.
+// When GitHub App is configured client authenticates as app installation,
+// otherwise with personal access token. Error detecting repository from
+// git remote is returned when owner or repo is not configured.
+func (gh *Github) Client(ctx context.Context) (*Client, error) {
+	gh.mu.Lock()
+	defer gh.mu.Unlock()
+	if gh.remoteErr != nil && (gh.owner == "" || gh.repo == "") {
+		return nil, gh.remoteErr
+	}
+	if gh.client != nil {
+		return gh.client, nil
+	}
+
+	var (
+		token string
+		opts  []ClientOption
+	)
+	if gh.baseURL != "" {
+		opts = append(opts, WithBaseURL(gh.baseURL))
+	}
+	if gh.app.id != 0 {
+		ts, err := NewAppTokenSource(gh.app.id, gh.app.installationID, gh.app.privateKey, gh.owner, gh.repo)
+		if err != nil {
+			return nil, err
+		}
+		if gh.baseURL != "" {
+			ts.SetBaseURL(gh.baseURL)
+		}
+		opts = append(opts, WithTokenSource(ts))
+	} else {
+		var err error
+		if token, err = ResolveToken(ctx, gh.token); err != nil {
+			return nil, err
+		}
+	}
+
+	client, err := NewClient(token, opts...)
+	if err != nil {
+		return nil, err
+	}
+	gh.client = client
+	return client, nil
+}
+
+// Publisher returns publisher creating releases in the configured repository.
+func (gh *Github) Publisher(ctx context.Context) (*Publisher, error) {
+	client, err := gh.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gh.mu.Lock()
+	opts := []PublisherOption{
+		WithDraft(gh.draft),
+		WithMilestones(gh.milestones),
+		WithDiscussion(gh.discussion),
+	}
+	if gh.prerelease != "" {
+		opts = append(opts, WithPrerelease(gh.prerelease))
+	}
+	if gh.notes != "" {
+		opts = append(opts, WithNotes(gh.notes))
+	}
+	gh.mu.Unlock()
+	return NewPublisher(client, gh.Owner(), gh.Repo(), opts...), nil
+}
+
+// ChecksGate returns gate checking required checks of protected branch.
+func (gh *Github) ChecksGate(ctx context.Context, branch string) (*ChecksGate, error) {
+	client, err := gh.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewChecksGate(client, gh.Owner(), gh.Repo(), branch), nil
+}
+
+// AlertsGate returns release precondition checking open security alerts
+// as configured by alerts.gate and alerts.severity settings.
+func (gh *Github) AlertsGate(ctx context.Context) (*AlertsGate, error) {
+	client, err := gh.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gate := NewAlertsGate(client, gh.Owner(), gh.Repo())
+	gh.mu.Lock()
+	defer gh.mu.Unlock()
+	if gh.alerts.mode != "" {
+		gate.Mode = gh.alerts.mode
+	}
+	if gh.alerts.severity != "" {
+		gate.Severity = gh.alerts.severity
+	}
+	return gate, nil
+}
+
+// VerifyReleaseBranch returns warnings for branch protection policies
+// of branch the release flow would conflict with.
+func (gh *Github) VerifyReleaseBranch(ctx context.Context, branch string, flow ReleaseFlow) ([]string, error) {
+	client, err := gh.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.VerifyReleaseBranch(ctx, gh.Owner(), gh.Repo(), branch, flow)
+}
+
+// Stages returns reporter of release stages as check runs on commit sha.
+// Reporting is enabled with checks.enabled setting when running in
+// GitHub Actions, disabled reporter is no-op and needs no client or token.
+func (gh *Github) Stages(ctx context.Context, sha string) (*Stages, error) {
+	gh.mu.Lock()
+	enabled := gh.checks && InActions()
+	gh.mu.Unlock()
+	if !enabled {
+		return NewStages(nil, gh.Owner(), gh.Repo(), sha, "release", false), nil
+	}
+	client, err := gh.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewStages(client, gh.Owner(), gh.Repo(), sha, "release", true), nil
+}
+
+// Webhook returns webhook receiver verifying deliveries with the
+// configured webhook secret. Programs mount it on their http server,
+// without webhook.secret setting every delivery is rejected.
+func (gh *Github) Webhook() *Webhook {
+	gh.mu.Lock()
+	defer gh.mu.Unlock()
+	return NewWebhook(gh.webhook)
+}
+
+// OpenPullRequest opens pull request in the configured repository, see
+// Client.OpenPullRequest. Reviews are requested from code owners of
+// touched paths according to CODEOWNERS of the working directory repository.
+func (gh *Github) OpenPullRequest(ctx context.Context, spec PullRequestSpec, touched []string) (*PullRequest, error) {
+	client, err := gh.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(touched) > 0 {
+		root, err := repositoryRoot(ctx)
+		if err != nil {
+			return nil, err
+		}
+		co, err := LoadCodeowners(root)
+		if err != nil {
+			return nil, err
+		}
+		users, teams := co.Reviewers(touched)
+		// author can not be requested to review own pull request
+		if me, err := client.CurrentUser(ctx); err == nil {
+			users = slices.DeleteFunc(users, func(u string) bool {
+				return strings.EqualFold(u, me.Login)
+			})
+		}
+		spec.Reviewers = append(spec.Reviewers, users...)
+		spec.TeamReviewers = append(spec.TeamReviewers, teams...)
+	}
+	return client.OpenPullRequest(ctx, gh.Owner(), gh.Repo(), spec)
+}
+
+// SyncLabels reconciles repository labels with the label set defined in
+// labels file. In dry run changes are only reported.
+func (gh *Github) SyncLabels(ctx context.Context, dryRun bool) ([]LabelChange, error) {
+	gh.mu.Lock()
+	ls := gh.labels
+	gh.mu.Unlock()
+	desired, err := LoadLabels(ls.file)
+	if err != nil {
+		return nil, err
+	}
+	client, err := gh.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.SyncLabels(ctx, gh.Owner(), gh.Repo(), desired, LabelSyncOptions{
+		DryRun: dryRun,
+		Prune:  ls.prune,
+	})
+}
+
+func setting(sess *happy.Session, key string) string {
+	return sess.Settings().Get("github." + key).Value().String()
+}
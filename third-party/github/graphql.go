@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GraphQLError is an error returned by GraphQL API.
+type GraphQLError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	Path    []any  `json:"path"`
+}
+
+// GraphQLErrors are errors returned alongside GraphQL response.
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Message)
+	}
+	return fmt.Sprintf("%s: graphql: %s", Error, strings.Join(msgs, "; "))
+}
+
+func (e GraphQLErrors) Unwrap() error {
+	for _, err := range e {
+		if err.Type == "NOT_FOUND" {
+			return ErrNotFound
+		}
+	}
+	return Error
+}
+
+// GraphQL executes query with variables and decodes response data into
+// out. Requests share authentication and rate limit handling with REST API.
+func (c *Client) GraphQL(ctx context.Context, query string, vars map[string]any, out any) error {
+	req, err := c.NewRequest(ctx, http.MethodPost, c.graphqlPath(), map[string]any{
+		"query":     query,
+		"variables": vars,
+	})
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors GraphQLErrors   `json:"errors"`
+	}
+	if _, err := c.Do(req, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		return resp.Errors
+	}
+	if out == nil || len(resp.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Data, out); err != nil {
+		return fmt.Errorf("%w: %s", ErrReadBody, err)
+	}
+	return nil
+}
+
+// graphqlPath returns GraphQL endpoint relative to the base url,
+// GitHub Enterprise serves REST under /api/v3 and GraphQL under /api/graphql.
+func (c *Client) graphqlPath() string {
+	if strings.HasSuffix(c.baseURL.Path, "/v3/") {
+		return "../graphql"
+	}
+	return "graphql"
+}
+
+// RepositoryNodeID returns GraphQL node id of repository owner/repo.
+func (c *Client) RepositoryNodeID(ctx context.Context, owner, repo string) (string, error) {
+	var out struct {
+		Repository struct {
+			ID string `json:"id"`
+		} `json:"repository"`
+	}
+	const query = `query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) { id }
+}`
+	if err := c.GraphQL(ctx, query, map[string]any{"owner": owner, "name": repo}, &out); err != nil {
+		return "", err
+	}
+	return out.Repository.ID, nil
+}
+
+// DiscussionCategory is a category of repository discussions.
+type DiscussionCategory struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// DiscussionCategories returns discussion categories of owner/repo.
+func (c *Client) DiscussionCategories(ctx context.Context, owner, repo string) ([]DiscussionCategory, error) {
+	var out struct {
+		Repository struct {
+			DiscussionCategories struct {
+				Nodes []DiscussionCategory `json:"nodes"`
+			} `json:"discussionCategories"`
+		} `json:"repository"`
+	}
+	const query = `query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) {
+    discussionCategories(first: 100) { nodes { id name slug } }
+  }
+}`
+	if err := c.GraphQL(ctx, query, map[string]any{"owner": owner, "name": repo}, &out); err != nil {
+		return nil, err
+	}
+	return out.Repository.DiscussionCategories.Nodes, nil
+}
+
+// Discussion is a repository discussion.
+type Discussion struct {
+	ID     string `json:"id"`
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+}
+
+// CreateDiscussion creates discussion in category of repository, both
+// referenced by GraphQL node id.
+func (c *Client) CreateDiscussion(ctx context.Context, repoID, categoryID, title, body string) (*Discussion, error) {
+	var out struct {
+		CreateDiscussion struct {
+			Discussion Discussion `json:"discussion"`
+		} `json:"createDiscussion"`
+	}
+	const mutation = `mutation($repo: ID!, $category: ID!, $title: String!, $body: String!) {
+  createDiscussion(input: {repositoryId: $repo, categoryId: $category, title: $title, body: $body}) {
+    discussion { id number url }
+  }
+}`
+	vars := map[string]any{
+		"repo":     repoID,
+		"category": categoryID,
+		"title":    title,
+		"body":     body,
+	}
+	if err := c.GraphQL(ctx, mutation, vars, &out); err != nil {
+		return nil, err
+	}
+	return &out.CreateDiscussion.Discussion, nil
+}
+
+// AnnounceRelease posts release announcement to discussion category of
+// owner/repo, category is matched by name or slug.
+func (c *Client) AnnounceRelease(ctx context.Context, owner, repo, category string, rel *Release) (*Discussion, error) {
+	categories, err := c.DiscussionCategories(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	var categoryID string
+	for _, cat := range categories {
+		if strings.EqualFold(cat.Name, category) || cat.Slug == category {
+			categoryID = cat.ID
+			break
+		}
+	}
+	if categoryID == "" {
+		return nil, fmt.Errorf("%w: discussion category %q", ErrNotFound, category)
+	}
+	repoID, err := c.RepositoryNodeID(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	name := rel.Name
+	if name == "" {
+		name = rel.TagName
+	}
+	body := strings.TrimRight(rel.Body, "\n")
+	if rel.HTMLURL != "" {
+		body += fmt.Sprintf("\n\nFull release: %s\n", rel.HTMLURL)
+	}
+	return c.CreateDiscussion(ctx, repoID, categoryID, "Release "+name, body)
+}
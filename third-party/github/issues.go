@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Label is an issue or pull request label.
+type Label struct {
+	ID          int64  `json:"id,omitempty"`
+	Name        string `json:"name"`
+	Color       string `json:"color,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Issue is a GitHub issue.
+type Issue struct {
+	ID        int64     `json:"id"`
+	Number    int       `json:"number"`
+	State     string    `json:"state"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	User      User      `json:"user"`
+	Labels    []Label   `json:"labels"`
+	Assignees []User    `json:"assignees"`
+	HTMLURL   string    `json:"html_url"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// PullRequest is set when issue is a pull request.
+	PullRequest *struct {
+		URL string `json:"url"`
+	} `json:"pull_request,omitempty"`
+}
+
+// IssueParams are the parameters to create or update an issue.
+type IssueParams struct {
+	Title     string   `json:"title,omitempty"`
+	Body      string   `json:"body,omitempty"`
+	State     string   `json:"state,omitempty"`
+	Labels    []string `json:"labels,omitempty"`
+	Assignees []string `json:"assignees,omitempty"`
+	Milestone *int     `json:"milestone,omitempty"`
+}
+
+// IssueListOptions filter issues returned by ListIssues.
+type IssueListOptions struct {
+	// State is one of open, closed or all. Defaults to open.
+	State    string
+	Labels   []string
+	Assignee string
+	// Milestone is milestone number, "*" for any or "none".
+	Milestone string
+	// Limit is maximum number of issues returned, all when zero.
+	Limit int
+}
+
+// IssueComment is a comment on an issue or pull request.
+type IssueComment struct {
+	ID        int64     `json:"id"`
+	Body      string    `json:"body"`
+	User      User      `json:"user"`
+	HTMLURL   string    `json:"html_url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ListIssues returns issues of repository, pull requests are excluded.
+func (c *Client) ListIssues(ctx context.Context, owner, repo string, opts IssueListOptions) ([]Issue, error) {
+	q := url.Values{}
+	if opts.State != "" {
+		q.Set("state", opts.State)
+	}
+	if len(opts.Labels) > 0 {
+		q.Set("labels", strings.Join(opts.Labels, ","))
+	}
+	if opts.Assignee != "" {
+		q.Set("assignee", opts.Assignee)
+	}
+	if opts.Milestone != "" {
+		q.Set("milestone", opts.Milestone)
+	}
+	// pull requests are filtered out, so pages are read until limit
+	// of issues is reached
+	q.Set("per_page", "100")
+
+	var issues []Issue
+	err := listPages(ctx, c, repoPath(owner, repo, "issues")+"?"+q.Encode(), func(items []Issue) bool {
+		for _, issue := range items {
+			if issue.PullRequest != nil {
+				continue
+			}
+			issues = append(issues, issue)
+			if len(issues) == opts.Limit {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// GetIssue returns issue number.
+func (c *Client) GetIssue(ctx context.Context, owner, repo string, number int) (*Issue, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, repoPath(owner, repo, "issues", fmt.Sprint(number)), nil)
+	if err != nil {
+		return nil, err
+	}
+	issue := &Issue{}
+	if _, err := c.Do(req, issue); err != nil {
+		return nil, err
+	}
+	return issue, nil
+}
+
+// CreateIssue creates a new issue.
+func (c *Client) CreateIssue(ctx context.Context, owner, repo string, params IssueParams) (*Issue, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, repoPath(owner, repo, "issues"), params)
+	if err != nil {
+		return nil, err
+	}
+	issue := &Issue{}
+	if _, err := c.Do(req, issue); err != nil {
+		return nil, err
+	}
+	return issue, nil
+}
+
+// UpdateIssue updates issue number, zero value params are left unchanged.
+func (c *Client) UpdateIssue(ctx context.Context, owner, repo string, number int, params IssueParams) (*Issue, error) {
+	req, err := c.NewRequest(ctx, http.MethodPatch, repoPath(owner, repo, "issues", fmt.Sprint(number)), params)
+	if err != nil {
+		return nil, err
+	}
+	issue := &Issue{}
+	if _, err := c.Do(req, issue); err != nil {
+		return nil, err
+	}
+	return issue, nil
+}
+
+// CloseIssue closes issue number.
+func (c *Client) CloseIssue(ctx context.Context, owner, repo string, number int) (*Issue, error) {
+	return c.UpdateIssue(ctx, owner, repo, number, IssueParams{State: "closed"})
+}
+
+// CreateIssueComment comments on issue or pull request number.
+func (c *Client) CreateIssueComment(ctx context.Context, owner, repo string, number int, body string) (*IssueComment, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, repoPath(owner, repo, "issues", fmt.Sprint(number), "comments"), map[string]string{
+		"body": body,
+	})
+	if err != nil {
+		return nil, err
+	}
+	comment := &IssueComment{}
+	if _, err := c.Do(req, comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// AddLabels adds labels to issue or pull request number.
+func (c *Client) AddLabels(ctx context.Context, owner, repo string, number int, labels ...string) ([]Label, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, repoPath(owner, repo, "issues", fmt.Sprint(number), "labels"), map[string][]string{
+		"labels": labels,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var out []Label
+	if _, err := c.Do(req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AddAssignees assigns users to issue or pull request number.
+func (c *Client) AddAssignees(ctx context.Context, owner, repo string, number int, assignees ...string) (*Issue, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, repoPath(owner, repo, "issues", fmt.Sprint(number), "assignees"), map[string][]string{
+		"assignees": assignees,
+	})
+	if err != nil {
+		return nil, err
+	}
+	issue := &Issue{}
+	if _, err := c.Do(req, issue); err != nil {
+		return nil, err
+	}
+	return issue, nil
+}
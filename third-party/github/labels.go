@@ -0,0 +1,179 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Label sync actions.
+const (
+	LabelCreate = "create"
+	LabelUpdate = "update"
+	LabelDelete = "delete"
+)
+
+// LabelChange is a change applied, or to be applied in dry run, by SyncLabels.
+type LabelChange struct {
+	Action string
+	Label  Label
+	// Old is the current label for update and delete.
+	Old Label
+}
+
+func (c LabelChange) String() string {
+	switch c.Action {
+	case LabelCreate:
+		return fmt.Sprintf("+ %s #%s %q", c.Label.Name, c.Label.Color, c.Label.Description)
+	case LabelDelete:
+		return fmt.Sprintf("- %s", c.Old.Name)
+	}
+	return fmt.Sprintf("~ %s #%s %q -> %s #%s %q",
+		c.Old.Name, c.Old.Color, c.Old.Description,
+		c.Label.Name, c.Label.Color, c.Label.Description)
+}
+
+// LabelSyncOptions control SyncLabels.
+type LabelSyncOptions struct {
+	// DryRun only computes changes without applying them.
+	DryRun bool
+	// Prune deletes labels which are not in the desired set.
+	Prune bool
+}
+
+// ListLabels returns labels of repository.
+func (c *Client) ListLabels(ctx context.Context, owner, repo string) ([]Label, error) {
+	return listAll[Label](ctx, c, repoPath(owner, repo, "labels")+"?per_page=100")
+}
+
+// CreateLabel creates a new label.
+func (c *Client) CreateLabel(ctx context.Context, owner, repo string, label Label) (*Label, error) {
+	label.ID = 0
+	req, err := c.NewRequest(ctx, http.MethodPost, repoPath(owner, repo, "labels"), label)
+	if err != nil {
+		return nil, err
+	}
+	out := &Label{}
+	if _, err := c.Do(req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateLabel updates label name, renaming it when label.Name differs.
+func (c *Client) UpdateLabel(ctx context.Context, owner, repo, name string, label Label) (*Label, error) {
+	body := map[string]string{
+		"new_name":    label.Name,
+		"color":       label.Color,
+		"description": label.Description,
+	}
+	req, err := c.NewRequest(ctx, http.MethodPatch, repoPath(owner, repo, "labels", url.PathEscape(name)), body)
+	if err != nil {
+		return nil, err
+	}
+	out := &Label{}
+	if _, err := c.Do(req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteLabel deletes label name.
+func (c *Client) DeleteLabel(ctx context.Context, owner, repo, name string) error {
+	req, err := c.NewRequest(ctx, http.MethodDelete, repoPath(owner, repo, "labels", url.PathEscape(name)), nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.Do(req, nil)
+	return err
+}
+
+// SyncLabels reconciles repository labels with desired label set. Labels
+// are matched by case insensitive name. Returns changes made or, in dry
+// run, changes which would be made.
+func (c *Client) SyncLabels(ctx context.Context, owner, repo string, desired []Label, opts LabelSyncOptions) ([]LabelChange, error) {
+	current, err := c.ListLabels(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	changes := DiffLabels(current, desired, opts.Prune)
+	if opts.DryRun {
+		return changes, nil
+	}
+	for i, change := range changes {
+		switch change.Action {
+		case LabelCreate:
+			_, err = c.CreateLabel(ctx, owner, repo, change.Label)
+		case LabelUpdate:
+			_, err = c.UpdateLabel(ctx, owner, repo, change.Old.Name, change.Label)
+		case LabelDelete:
+			err = c.DeleteLabel(ctx, owner, repo, change.Old.Name)
+		}
+		if err != nil {
+			return changes[:i], fmt.Errorf("%w: label %s: %w", Error, change, err)
+		}
+	}
+	return changes, nil
+}
+
+// DiffLabels returns changes needed to turn current labels into desired.
+func DiffLabels(current, desired []Label, prune bool) []LabelChange {
+	byName := make(map[string]Label, len(current))
+	for _, l := range current {
+		byName[strings.ToLower(l.Name)] = l
+	}
+
+	var changes []LabelChange
+	wanted := make(map[string]bool, len(desired))
+	for _, l := range desired {
+		l.Color = strings.ToLower(strings.TrimPrefix(l.Color, "#"))
+		key := strings.ToLower(l.Name)
+		wanted[key] = true
+		old, ok := byName[key]
+		switch {
+		case !ok:
+			changes = append(changes, LabelChange{Action: LabelCreate, Label: l})
+		case old.Name != l.Name || !strings.EqualFold(old.Color, l.Color) || old.Description != l.Description:
+			changes = append(changes, LabelChange{Action: LabelUpdate, Label: l, Old: old})
+		}
+	}
+	if prune {
+		var deletes []LabelChange
+		for key, l := range byName {
+			if !wanted[key] {
+				deletes = append(deletes, LabelChange{Action: LabelDelete, Old: l})
+			}
+		}
+		sort.Slice(deletes, func(i, j int) bool { return deletes[i].Old.Name < deletes[j].Old.Name })
+		changes = append(changes, deletes...)
+	}
+	return changes
+}
+
+// LoadLabels reads desired label set from JSON file containing array
+// of objects with name, color and description.
+func LoadLabels(path string) ([]Label, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var labels []Label
+	if err := json.Unmarshal(data, &labels); err != nil {
+		return nil, fmt.Errorf("%w: invalid labels file %s: %s", Error, path, err)
+	}
+	for _, l := range labels {
+		if l.Name == "" {
+			return nil, fmt.Errorf("%w: invalid labels file %s: label without name", Error, path)
+		}
+	}
+	return labels, nil
+}
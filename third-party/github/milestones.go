@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Milestone is a GitHub milestone.
+type Milestone struct {
+	ID           int64      `json:"id"`
+	Number       int        `json:"number"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	State        string     `json:"state"`
+	OpenIssues   int        `json:"open_issues"`
+	ClosedIssues int        `json:"closed_issues"`
+	HTMLURL      string     `json:"html_url"`
+	DueOn        *time.Time `json:"due_on"`
+}
+
+// MilestoneParams are the parameters to create or update a milestone.
+type MilestoneParams struct {
+	Title       string     `json:"title,omitempty"`
+	Description string     `json:"description,omitempty"`
+	State       string     `json:"state,omitempty"`
+	DueOn       *time.Time `json:"due_on,omitempty"`
+}
+
+// ListMilestones returns milestones in state open, closed or all.
+func (c *Client) ListMilestones(ctx context.Context, owner, repo, state string) ([]Milestone, error) {
+	if state == "" {
+		state = "open"
+	}
+	return listAll[Milestone](ctx, c, repoPath(owner, repo, "milestones")+"?per_page=100&state="+state)
+}
+
+// CreateMilestone creates a new milestone.
+func (c *Client) CreateMilestone(ctx context.Context, owner, repo string, params MilestoneParams) (*Milestone, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, repoPath(owner, repo, "milestones"), params)
+	if err != nil {
+		return nil, err
+	}
+	m := &Milestone{}
+	if _, err := c.Do(req, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// UpdateMilestone updates milestone number, zero value params are left unchanged.
+func (c *Client) UpdateMilestone(ctx context.Context, owner, repo string, number int, params MilestoneParams) (*Milestone, error) {
+	req, err := c.NewRequest(ctx, http.MethodPatch, repoPath(owner, repo, "milestones", fmt.Sprint(number)), params)
+	if err != nil {
+		return nil, err
+	}
+	m := &Milestone{}
+	if _, err := c.Do(req, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CloseMilestone closes milestone number.
+func (c *Client) CloseMilestone(ctx context.Context, owner, repo string, number int) (*Milestone, error) {
+	return c.UpdateMilestone(ctx, owner, repo, number, MilestoneParams{State: "closed"})
+}
+
+// FindReleaseMilestone returns milestone matching released tag, open
+// milestone is preferred over closed one completed by previous release.
+// Milestone title may be the tag itself or its version with or without
+// "v" prefix, e.g. "pkg/v1.2.0", "v1.2.0" or "1.2.0".
+// Returns nil milestone when there is no match.
+func (c *Client) FindReleaseMilestone(ctx context.Context, owner, repo, tag string) (*Milestone, error) {
+	milestones, err := c.ListMilestones(ctx, owner, repo, "all")
+	if err != nil {
+		return nil, err
+	}
+	version := tag
+	if i := strings.LastIndex(version, "/"); i >= 0 {
+		version = version[i+1:]
+	}
+	var found *Milestone
+	for i := range milestones {
+		title := strings.TrimSpace(milestones[i].Title)
+		if title != tag && title != version && "v"+title != version {
+			continue
+		}
+		if milestones[i].State == "open" {
+			return &milestones[i], nil
+		}
+		if found == nil {
+			found = &milestones[i]
+		}
+	}
+	return found, nil
+}
+
+// CompleteMilestone moves open issues of milestone to the next open
+// milestone, the one due soonest, and closes it. Returns next milestone,
+// nil if there was none and open issues were left in place.
+func (c *Client) CompleteMilestone(ctx context.Context, owner, repo string, m *Milestone) (*Milestone, error) {
+	milestones, err := c.ListMilestones(ctx, owner, repo, "open")
+	if err != nil {
+		return nil, err
+	}
+	var next *Milestone
+	for i := range milestones {
+		cand := &milestones[i]
+		if cand.Number == m.Number {
+			continue
+		}
+		if next == nil || dueBefore(cand, next) {
+			next = cand
+		}
+	}
+
+	if next != nil && m.OpenIssues > 0 {
+		issues, err := c.ListIssues(ctx, owner, repo, IssueListOptions{
+			State:     "open",
+			Milestone: fmt.Sprint(m.Number),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			if _, err := c.UpdateIssue(ctx, owner, repo, issue.Number, IssueParams{Milestone: &next.Number}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if _, err := c.CloseMilestone(ctx, owner, repo, m.Number); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// dueBefore orders milestones by due date, milestones without due date
+// last, ties broken by creation order.
+func dueBefore(a, b *Milestone) bool {
+	switch {
+	case a.DueOn != nil && b.DueOn != nil && !a.DueOn.Equal(*b.DueOn):
+		return a.DueOn.Before(*b.DueOn)
+	case a.DueOn != nil && b.DueOn == nil:
+		return true
+	case a.DueOn == nil && b.DueOn != nil:
+		return false
+	}
+	return a.Number < b.Number
+}
@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type enabledSetting struct {
+	Enabled bool `json:"enabled"`
+}
+
+// BranchProtection is classic branch protection of a branch.
+type BranchProtection struct {
+	RequiredPullRequestReviews *struct {
+		RequiredApprovingReviewCount int  `json:"required_approving_review_count"`
+		RequireCodeOwnerReviews      bool `json:"require_code_owner_reviews"`
+	} `json:"required_pull_request_reviews"`
+	RequiredStatusChecks *struct {
+		Strict   bool     `json:"strict"`
+		Contexts []string `json:"contexts"`
+	} `json:"required_status_checks"`
+	RequiredLinearHistory enabledSetting `json:"required_linear_history"`
+	RequiredSignatures    enabledSetting `json:"required_signatures"`
+	EnforceAdmins         enabledSetting `json:"enforce_admins"`
+	AllowForcePushes      enabledSetting `json:"allow_force_pushes"`
+	Restrictions          *struct {
+		Users []User `json:"users"`
+		Teams []struct {
+			Slug string `json:"slug"`
+		} `json:"teams"`
+	} `json:"restrictions"`
+}
+
+// BranchRule is a repository ruleset rule active on a branch.
+type BranchRule struct {
+	Type       string         `json:"type"`
+	Parameters map[string]any `json:"parameters,omitempty"`
+}
+
+// GetBranchProtection returns classic protection of branch, nil when
+// branch is not protected. Reading protection requires admin permission.
+func (c *Client) GetBranchProtection(ctx context.Context, owner, repo, branch string) (*BranchProtection, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, repoPath(owner, repo, "branches", url.PathEscape(branch), "protection"), nil)
+	if err != nil {
+		return nil, err
+	}
+	bp := &BranchProtection{}
+	if _, err := c.Do(req, bp); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return bp, nil
+}
+
+// BranchRules returns ruleset rules active on branch, readable
+// with read permission.
+func (c *Client) BranchRules(ctx context.Context, owner, repo, branch string) ([]BranchRule, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, repoPath(owner, repo, "rules", "branches", url.PathEscape(branch))+"?per_page=100", nil)
+	if err != nil {
+		return nil, err
+	}
+	var rules []BranchRule
+	if _, err := c.Do(req, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// ReleaseFlow describes what the local release flow does on release branch.
+type ReleaseFlow struct {
+	// PushesCommits is set when release pushes commits, e.g. changelog
+	// or version bumps, directly to the branch.
+	PushesCommits bool
+	// SignsCommits is set when release commits are signed.
+	SignsCommits bool
+	// MergeCommits is set when release creates merge commits.
+	MergeCommits bool
+}
+
+// VerifyReleaseBranch inspects classic protection and rulesets of branch
+// and returns warnings for every policy the release flow would conflict
+// with. Classic protection is skipped when token lacks admin permission.
+func (c *Client) VerifyReleaseBranch(ctx context.Context, owner, repo, branch string, flow ReleaseFlow) ([]string, error) {
+	var (
+		reviews, linear, signatures bool
+		restricted                  []string
+	)
+
+	bp, err := c.GetBranchProtection(ctx, owner, repo, branch)
+	var errResp *ErrorResponse
+	switch {
+	case errors.As(err, &errResp) && errResp.Response.StatusCode == http.StatusForbidden && !errors.Is(err, ErrRateLimit):
+	case err != nil:
+		return nil, err
+	case bp != nil:
+		reviews = bp.RequiredPullRequestReviews != nil
+		linear = bp.RequiredLinearHistory.Enabled
+		signatures = bp.RequiredSignatures.Enabled
+		if bp.Restrictions != nil {
+			for _, u := range bp.Restrictions.Users {
+				restricted = append(restricted, u.Login)
+			}
+			for _, t := range bp.Restrictions.Teams {
+				restricted = append(restricted, "team "+t.Slug)
+			}
+			if len(restricted) == 0 {
+				restricted = append(restricted, "nobody")
+			}
+		}
+	}
+
+	rules, err := c.BranchRules(ctx, owner, repo, branch)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	for _, r := range rules {
+		switch r.Type {
+		case "pull_request":
+			reviews = true
+		case "required_linear_history":
+			linear = true
+		case "required_signatures":
+			signatures = true
+		case "update":
+			restricted = append(restricted, "ruleset bypass actors")
+		}
+	}
+
+	var warnings []string
+	if flow.PushesCommits {
+		if reviews {
+			warnings = append(warnings, fmt.Sprintf("%s requires pull request reviews, direct push of release commits will be rejected", branch))
+		}
+		if signatures && !flow.SignsCommits {
+			warnings = append(warnings, fmt.Sprintf("%s requires signed commits, release commits are not signed", branch))
+		}
+		if len(restricted) > 0 {
+			warnings = append(warnings, fmt.Sprintf("push to %s is restricted to %s", branch, strings.Join(restricted, ", ")))
+		}
+	}
+	if linear && flow.MergeCommits {
+		warnings = append(warnings, fmt.Sprintf("%s requires linear history, release merge commits will be rejected", branch))
+	}
+	return warnings, nil
+}
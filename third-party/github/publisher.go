@@ -0,0 +1,308 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PublishRequest describes a single pushed tag to publish as GitHub Release.
+type PublishRequest struct {
+	// Tag is the pushed git tag e.g. "v1.2.0" or "pkg/sub/v0.3.1".
+	Tag string
+	// Name of the release, defaults to Tag.
+	Name string
+	// Notes is the changelog of the released module, used as release body.
+	Notes string
+	// PreviousTag is the previous release tag of the module, used as start
+	// of the range when release notes are generated by GitHub.
+	PreviousTag string
+	// Assets are paths of dist artifacts to attach to the release.
+	Assets []string
+	// Attestations are paths of Sigstore bundles (SLSA provenance,
+	// signatures) of the released artifacts to upload as attestations.
+	// Bundles already attested with same predicate type are skipped.
+	Attestations []string
+	// Draft overrides publisher draft option when not nil.
+	Draft *bool
+	// Prerelease overrides publisher prerelease mode when not nil.
+	Prerelease *bool
+}
+
+// Prerelease modes of Publisher.
+const (
+	PrereleaseAuto   = "auto"
+	PrereleaseAlways = "always"
+	PrereleaseNever  = "never"
+)
+
+// Release notes modes of Publisher.
+const (
+	// NotesChangelog uses local changelog as release body.
+	NotesChangelog = "changelog"
+	// NotesGitHub uses release notes generated by GitHub.
+	NotesGitHub = "github"
+	// NotesMerged uses local changelog followed by GitHub release notes.
+	NotesMerged = "merged"
+)
+
+// PublisherOption configures a Publisher.
+type PublisherOption func(p *Publisher)
+
+// WithDraft creates releases as drafts which must be promoted with
+// Publisher.Promote after manual review.
+func WithDraft(draft bool) PublisherOption {
+	return func(p *Publisher) {
+		p.draft = draft
+	}
+}
+
+// WithPrerelease sets prerelease mode, one of PrereleaseAuto,
+// PrereleaseAlways or PrereleaseNever. In auto mode tags having
+// semver pre-release suffix e.g. v1.2.0-rc.1 are marked as prerelease.
+func WithPrerelease(mode string) PublisherOption {
+	return func(p *Publisher) {
+		p.prerelease = mode
+	}
+}
+
+// WithMilestones completes milestone matching released version: open
+// issues are moved to the next milestone, milestone is closed and linked
+// in the release notes.
+func WithMilestones(enabled bool) PublisherOption {
+	return func(p *Publisher) {
+		p.milestones = enabled
+	}
+}
+
+// WithDiscussion announces published releases in discussion category,
+// empty category disables announcements.
+func WithDiscussion(category string) PublisherOption {
+	return func(p *Publisher) {
+		p.discussion = category
+	}
+}
+
+// WithNotes sets release notes mode, one of NotesChangelog, NotesGitHub
+// or NotesMerged.
+func WithNotes(mode string) PublisherOption {
+	return func(p *Publisher) {
+		p.notes = mode
+	}
+}
+
+// Publisher creates GitHub Releases for released tags.
+type Publisher struct {
+	client     *Client
+	owner      string
+	repo       string
+	draft      bool
+	prerelease string
+	notes      string
+	milestones bool
+	discussion string
+}
+
+// NewPublisher returns publisher creating releases in owner/repo.
+func NewPublisher(client *Client, owner, repo string, opts ...PublisherOption) *Publisher {
+	p := &Publisher{
+		client:     client,
+		owner:      owner,
+		repo:       repo,
+		prerelease: PrereleaseAuto,
+		notes:      NotesChangelog,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Promote publishes draft release of tag.
+func (p *Publisher) Promote(ctx context.Context, tag string) (*Release, error) {
+	rel, err := p.client.FindRelease(ctx, p.owner, p.repo, tag)
+	if err != nil {
+		return nil, err
+	}
+	if !rel.Draft {
+		return rel, nil
+	}
+	if rel, err = p.client.PublishDraftRelease(ctx, p.owner, p.repo, rel.ID); err != nil {
+		return nil, err
+	}
+	if err := p.announce(ctx, rel); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// Publish creates a release for every request. Publishing is idempotent,
+// when release for tag already exists its notes are updated and only
+// missing assets are uploaded.
+func (p *Publisher) Publish(ctx context.Context, reqs ...PublishRequest) ([]*Release, error) {
+	var rels []*Release
+	for _, r := range reqs {
+		rel, err := p.publish(ctx, r)
+		if err != nil {
+			return rels, fmt.Errorf("%w: publish %s: %w", Error, r.Tag, err)
+		}
+		rels = append(rels, rel)
+	}
+	return rels, nil
+}
+
+func (p *Publisher) publish(ctx context.Context, r PublishRequest) (*Release, error) {
+	if r.Tag == "" {
+		return nil, fmt.Errorf("%w: tag is required", Error)
+	}
+	body, err := p.releaseBody(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	var milestone *Milestone
+	if p.milestones {
+		if milestone, err = p.client.FindReleaseMilestone(ctx, p.owner, p.repo, r.Tag); err != nil {
+			return nil, err
+		}
+		if milestone != nil {
+			body = strings.TrimRight(body, "\n") + fmt.Sprintf("\n\nMilestone: [%s](%s)\n", milestone.Title, milestone.HTMLURL)
+		}
+	}
+	params := ReleaseParams{
+		TagName: r.Tag,
+		Name:    r.Name,
+		Body:    body,
+	}
+	if params.Name == "" {
+		params.Name = r.Tag
+	}
+	draft, prerelease := p.draft, p.isPrerelease(r.Tag)
+	if r.Draft != nil {
+		draft = *r.Draft
+	}
+	if r.Prerelease != nil {
+		prerelease = *r.Prerelease
+	}
+	params.Prerelease = &prerelease
+
+	created := false
+	rel, err := p.client.FindRelease(ctx, p.owner, p.repo, r.Tag)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		params.Draft = &draft
+		rel, err = p.client.CreateRelease(ctx, p.owner, p.repo, params)
+		created = true
+	case err == nil:
+		rel, err = p.client.UpdateRelease(ctx, p.owner, p.repo, rel.ID, params)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	uploaded := make(map[string]bool, len(rel.Assets))
+	for _, a := range rel.Assets {
+		uploaded[a.Name] = true
+	}
+	for _, path := range r.Assets {
+		if uploaded[filepath.Base(path)] {
+			continue
+		}
+		asset, err := p.client.UploadReleaseAsset(ctx, rel, path)
+		if err != nil {
+			return nil, err
+		}
+		rel.Assets = append(rel.Assets, *asset)
+	}
+
+	for _, path := range r.Attestations {
+		if err := p.attest(ctx, path); err != nil {
+			return nil, err
+		}
+	}
+
+	// milestone is already closed when publishing again
+	if milestone != nil && milestone.State == "open" {
+		if _, err := p.client.CompleteMilestone(ctx, p.owner, p.repo, milestone); err != nil {
+			return nil, err
+		}
+	}
+
+	// Announce only newly created releases, so publishing again does not
+	// post duplicate announcements. Drafts are announced on Promote.
+	if created && !rel.Draft {
+		if err := p.announce(ctx, rel); err != nil {
+			return nil, err
+		}
+	}
+	return rel, nil
+}
+
+// attest uploads Sigstore bundle at path unless its artifacts are
+// already attested by previous run.
+func (p *Publisher) attest(ctx context.Context, path string) error {
+	bundle, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	attested, err := p.client.Attested(ctx, p.owner, p.repo, bundle)
+	if err == nil && !attested {
+		_, err = p.client.UploadAttestation(ctx, p.owner, p.repo, bundle)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: attestation %s: %w", Error, path, err)
+	}
+	return nil
+}
+
+func (p *Publisher) announce(ctx context.Context, rel *Release) error {
+	if p.discussion == "" {
+		return nil
+	}
+	_, err := p.client.AnnounceRelease(ctx, p.owner, p.repo, p.discussion, rel)
+	return err
+}
+
+func (p *Publisher) releaseBody(ctx context.Context, r PublishRequest) (string, error) {
+	if p.notes != NotesGitHub && p.notes != NotesMerged {
+		return r.Notes, nil
+	}
+	notes, err := p.client.GenerateReleaseNotes(ctx, p.owner, p.repo, GenerateNotesParams{
+		TagName:         r.Tag,
+		PreviousTagName: r.PreviousTag,
+	})
+	if err != nil {
+		return "", err
+	}
+	if p.notes == NotesGitHub || r.Notes == "" {
+		return notes.Body, nil
+	}
+	return strings.TrimRight(r.Notes, "\n") + "\n\n" + notes.Body, nil
+}
+
+func (p *Publisher) isPrerelease(tag string) bool {
+	switch p.prerelease {
+	case PrereleaseAlways:
+		return true
+	case PrereleaseNever:
+		return false
+	}
+	return IsPrerelease(tag)
+}
+
+// IsPrerelease reports whether version of tag has semver pre-release
+// suffix. Module path prefix of monorepo tags e.g. "pkg/sub/v1.0.0-beta.1"
+// is ignored.
+func IsPrerelease(tag string) bool {
+	if i := strings.LastIndex(tag, "/"); i >= 0 {
+		tag = tag[i+1:]
+	}
+	tag, _, _ = strings.Cut(tag, "+")
+	return strings.Contains(tag, "-")
+}
@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// PullRequest is a GitHub pull request.
+type PullRequest struct {
+	ID        int64     `json:"id"`
+	Number    int       `json:"number"`
+	State     string    `json:"state"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	Draft     bool      `json:"draft"`
+	Merged    bool      `json:"merged"`
+	User      User      `json:"user"`
+	Labels    []Label   `json:"labels"`
+	HTMLURL   string    `json:"html_url"`
+	CreatedAt time.Time `json:"created_at"`
+	Head      PullRef   `json:"head"`
+	Base      PullRef   `json:"base"`
+}
+
+// PullRef is head or base branch of a pull request.
+type PullRef struct {
+	Label string `json:"label"`
+	Ref   string `json:"ref"`
+	SHA   string `json:"sha"`
+}
+
+// PullRequestParams are the parameters to create or update a pull request.
+type PullRequestParams struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+	// Head is the branch with changes, "owner:branch" for cross repository
+	// pull requests. Only used on create.
+	Head string `json:"head,omitempty"`
+	Base string `json:"base,omitempty"`
+	// Draft is only used on create.
+	Draft bool   `json:"draft,omitempty"`
+	State string `json:"state,omitempty"`
+}
+
+// PullRequestSpec describes pull request to open with OpenPullRequest.
+type PullRequestSpec struct {
+	PullRequestParams
+	Labels        []string
+	Reviewers     []string
+	TeamReviewers []string
+}
+
+// GetPullRequest returns pull request number.
+func (c *Client) GetPullRequest(ctx context.Context, owner, repo string, number int) (*PullRequest, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, repoPath(owner, repo, "pulls", fmt.Sprint(number)), nil)
+	if err != nil {
+		return nil, err
+	}
+	pr := &PullRequest{}
+	if _, err := c.Do(req, pr); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// FindPullRequest returns open pull request from head into base,
+// ErrNotFound if there is none.
+func (c *Client) FindPullRequest(ctx context.Context, owner, repo, head, base string) (*PullRequest, error) {
+	if !strings.Contains(head, ":") {
+		head = owner + ":" + head
+	}
+	q := url.Values{}
+	q.Set("state", "open")
+	q.Set("head", head)
+	if base != "" {
+		q.Set("base", base)
+	}
+	req, err := c.NewRequest(ctx, http.MethodGet, repoPath(owner, repo, "pulls")+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var prs []PullRequest
+	if _, err := c.Do(req, &prs); err != nil {
+		return nil, err
+	}
+	if len(prs) == 0 {
+		return nil, fmt.Errorf("%w: pull request %s", ErrNotFound, head)
+	}
+	return &prs[0], nil
+}
+
+// CreatePullRequest creates a new pull request.
+func (c *Client) CreatePullRequest(ctx context.Context, owner, repo string, params PullRequestParams) (*PullRequest, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, repoPath(owner, repo, "pulls"), params)
+	if err != nil {
+		return nil, err
+	}
+	pr := &PullRequest{}
+	if _, err := c.Do(req, pr); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// UpdatePullRequest updates pull request number, zero value params are
+// left unchanged.
+func (c *Client) UpdatePullRequest(ctx context.Context, owner, repo string, number int, params PullRequestParams) (*PullRequest, error) {
+	params.Head, params.Draft = "", false
+	req, err := c.NewRequest(ctx, http.MethodPatch, repoPath(owner, repo, "pulls", fmt.Sprint(number)), params)
+	if err != nil {
+		return nil, err
+	}
+	pr := &PullRequest{}
+	if _, err := c.Do(req, pr); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// RequestReviewers requests reviews from users and teams (team slugs).
+func (c *Client) RequestReviewers(ctx context.Context, owner, repo string, number int, users, teams []string) (*PullRequest, error) {
+	body := struct {
+		Reviewers     []string `json:"reviewers,omitempty"`
+		TeamReviewers []string `json:"team_reviewers,omitempty"`
+	}{users, teams}
+	req, err := c.NewRequest(ctx, http.MethodPost, repoPath(owner, repo, "pulls", fmt.Sprint(number), "requested_reviewers"), body)
+	if err != nil {
+		return nil, err
+	}
+	pr := &PullRequest{}
+	if _, err := c.Do(req, pr); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// OpenPullRequest creates pull request described by spec or updates title
+// and body of already open pull request for the same head and base.
+// Labels and reviewers are added to the pull request in both cases.
+func (c *Client) OpenPullRequest(ctx context.Context, owner, repo string, spec PullRequestSpec) (*PullRequest, error) {
+	pr, err := c.FindPullRequest(ctx, owner, repo, spec.Head, spec.Base)
+	switch {
+	case err == nil:
+		pr, err = c.UpdatePullRequest(ctx, owner, repo, pr.Number, PullRequestParams{
+			Title: spec.Title,
+			Body:  spec.Body,
+		})
+	case errors.Is(err, ErrNotFound):
+		pr, err = c.CreatePullRequest(ctx, owner, repo, spec.PullRequestParams)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(spec.Labels) > 0 {
+		labels, err := c.AddLabels(ctx, owner, repo, pr.Number, spec.Labels...)
+		if err != nil {
+			return nil, err
+		}
+		pr.Labels = labels
+	}
+	if len(spec.Reviewers) > 0 || len(spec.TeamReviewers) > 0 {
+		if _, err := c.RequestReviewers(ctx, owner, repo, pr.Number, spec.Reviewers, spec.TeamReviewers); err != nil {
+			return nil, err
+		}
+	}
+	return pr, nil
+}
@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package github
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRateLimitWait = 2 * time.Minute
+	defaultCacheSize     = 256
+	// secondaryRateLimitWait is used when secondary rate limit response
+	// has no Retry-After header, GitHub advises to wait at least a minute.
+	secondaryRateLimitWait = time.Minute
+	maxRateLimitRetries    = 3
+)
+
+// WithRateLimitWait sets the longest time client waits for rate limit
+// to reset before retrying. Requests needing to wait longer fail with
+// ErrRateLimit, zero disables waiting.
+func WithRateLimitWait(max time.Duration) ClientOption {
+	return func(c *Client) error {
+		c.rateLimitWait = max
+		return nil
+	}
+}
+
+// WithCacheSize sets how many GET responses are cached for conditional
+// requests, zero disables the cache.
+func WithCacheSize(n int) ClientOption {
+	return func(c *Client) error {
+		if n <= 0 {
+			c.cache = nil
+			return nil
+		}
+		c.cache = newResponseCache(n)
+		return nil
+	}
+}
+
+// RateLimit is the rate limit status reported with the last response.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// RateLimit returns rate limit status of the last response.
+func (c *Client) RateLimit() RateLimit {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rateLimit
+}
+
+func (c *Client) trackRateLimit(resp *http.Response) {
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	limit, _ := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+	reset, _ := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rateLimit = RateLimit{
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     time.Unix(reset, 0),
+	}
+}
+
+// rateLimited reports whether response was rejected by primary or
+// secondary rate limit and how long to wait before retrying.
+func rateLimited(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if ra := resp.Header.Get("Retry-After"); ra != "" {
+		if secs, err := strconv.Atoi(ra); err == nil {
+			return time.Duration(secs) * time.Second, true
+		}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Unix(reset, 0).Sub(now)+time.Second, 0), true
+		}
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return secondaryRateLimitWait, true
+	}
+
+	// Secondary rate limit may be reported as plain 403, recognizable
+	// only by the message, restore body so it can be read again.
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err == nil && strings.Contains(strings.ToLower(string(body)), "secondary rate limit") {
+		return secondaryRateLimitWait, true
+	}
+	return 0, false
+}
+
+type cachedResponse struct {
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+// responseCache keeps ETag validated GET responses. Responses to
+// conditional requests answered with 304 Not Modified do not count
+// against the rate limit.
+type responseCache struct {
+	mu      sync.Mutex
+	size    int
+	keys    []string
+	entries map[string]*cachedResponse
+}
+
+func newResponseCache(size int) *responseCache {
+	return &responseCache{
+		size:    size,
+		entries: make(map[string]*cachedResponse, size),
+	}
+}
+
+func cacheKey(req *http.Request) string {
+	return req.Header.Get("Authorization") + " " + req.Header.Get("Accept") + " " + req.URL.String()
+}
+
+func (rc *responseCache) get(key string) *cachedResponse {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.entries[key]
+}
+
+func (rc *responseCache) put(key string, entry *cachedResponse) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if _, ok := rc.entries[key]; !ok {
+		if len(rc.keys) >= rc.size {
+			delete(rc.entries, rc.keys[0])
+			rc.keys = rc.keys[1:]
+		}
+		rc.keys = append(rc.keys, key)
+	}
+	rc.entries[key] = entry
+}
+
+// store caches successful JSON response carrying ETag, response body is
+// replaced with in memory copy. Other content e.g. release assets
+// redirected to storage is left streaming.
+func (rc *responseCache) store(key string, resp *http.Response) error {
+	etag := resp.Header.Get("ETag")
+	if etag == "" || resp.StatusCode != http.StatusOK || !isJSON(resp.Header.Get("Content-Type")) {
+		return nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	rc.put(key, &cachedResponse{
+		etag:   etag,
+		header: resp.Header.Clone(),
+		body:   body,
+	})
+	return nil
+}
+
+// isJSON reports whether media type of Accept or Content-Type header is
+// JSON, including GitHub vendor types e.g. "application/vnd.github+json".
+func isJSON(mediaType string) bool {
+	mt, _, _ := strings.Cut(mediaType, ";")
+	mt = strings.TrimSpace(mt)
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
+
+// response returns cached response in place of 304 Not Modified.
+func (cr *cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        cr.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(cr.body)),
+		ContentLength: int64(len(cr.body)),
+		Request:       req,
+	}
+}
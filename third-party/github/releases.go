@@ -0,0 +1,168 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Release is a GitHub release.
+type Release struct {
+	ID              int64          `json:"id"`
+	TagName         string         `json:"tag_name"`
+	TargetCommitish string         `json:"target_commitish,omitempty"`
+	Name            string         `json:"name"`
+	Body            string         `json:"body"`
+	Draft           bool           `json:"draft"`
+	Prerelease      bool           `json:"prerelease"`
+	HTMLURL         string         `json:"html_url"`
+	UploadURL       string         `json:"upload_url"`
+	CreatedAt       time.Time      `json:"created_at"`
+	PublishedAt     time.Time      `json:"published_at"`
+	Assets          []ReleaseAsset `json:"assets"`
+}
+
+// ReleaseAsset is a file attached to a release.
+type ReleaseAsset struct {
+	ID                 int64  `json:"id"`
+	Name               string `json:"name"`
+	Label              string `json:"label,omitempty"`
+	ContentType        string `json:"content_type"`
+	Size               int64  `json:"size"`
+	State              string `json:"state"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// ReleaseParams are the parameters to create or update a release.
+type ReleaseParams struct {
+	TagName         string `json:"tag_name,omitempty"`
+	TargetCommitish string `json:"target_commitish,omitempty"`
+	Name            string `json:"name,omitempty"`
+	Body            string `json:"body,omitempty"`
+	Draft           *bool  `json:"draft,omitempty"`
+	Prerelease      *bool  `json:"prerelease,omitempty"`
+}
+
+// CreateRelease creates a new release.
+func (c *Client) CreateRelease(ctx context.Context, owner, repo string, params ReleaseParams) (*Release, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, repoPath(owner, repo, "releases"), params)
+	if err != nil {
+		return nil, err
+	}
+	rel := &Release{}
+	if _, err := c.Do(req, rel); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// UpdateRelease updates release id, zero value params are left unchanged.
+func (c *Client) UpdateRelease(ctx context.Context, owner, repo string, id int64, params ReleaseParams) (*Release, error) {
+	req, err := c.NewRequest(ctx, http.MethodPatch, repoPath(owner, repo, "releases", fmt.Sprint(id)), params)
+	if err != nil {
+		return nil, err
+	}
+	rel := &Release{}
+	if _, err := c.Do(req, rel); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// GetReleaseByTag returns the published release of tag, ErrNotFound if
+// there is none. Draft releases are never returned, use FindRelease to
+// include them.
+func (c *Client) GetReleaseByTag(ctx context.Context, owner, repo, tag string) (*Release, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, repoPath(owner, repo, "releases", "tags", url.PathEscape(tag)), nil)
+	if err != nil {
+		return nil, err
+	}
+	rel := &Release{}
+	if _, err := c.Do(req, rel); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// FindRelease returns the release of tag including drafts, ErrNotFound
+// if there is none. Drafts are not reachable by tag, so releases are
+// listed and matched by tag_name.
+func (c *Client) FindRelease(ctx context.Context, owner, repo, tag string) (*Release, error) {
+	var found *Release
+	err := listPages(ctx, c, repoPath(owner, repo, "releases")+"?per_page=100", func(rels []Release) bool {
+		for i := range rels {
+			if rels[i].TagName == tag {
+				found = &rels[i]
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: release %s", ErrNotFound, tag)
+	}
+	return found, nil
+}
+
+// ListReleases returns the most recent releases, newest first.
+func (c *Client) ListReleases(ctx context.Context, owner, repo string, limit int) ([]Release, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	req, err := c.NewRequest(ctx, http.MethodGet, repoPath(owner, repo, "releases")+fmt.Sprintf("?per_page=%d", limit), nil)
+	if err != nil {
+		return nil, err
+	}
+	var rels []Release
+	if _, err := c.Do(req, &rels); err != nil {
+		return nil, err
+	}
+	return rels, nil
+}
+
+// PublishDraftRelease publishes draft release id.
+func (c *Client) PublishDraftRelease(ctx context.Context, owner, repo string, id int64) (*Release, error) {
+	draft := false
+	return c.UpdateRelease(ctx, owner, repo, id, ReleaseParams{Draft: &draft})
+}
+
+// GenerateNotesParams are the parameters to generate release notes.
+type GenerateNotesParams struct {
+	TagName         string `json:"tag_name"`
+	TargetCommitish string `json:"target_commitish,omitempty"`
+	// PreviousTagName is start of the range, required for monorepo
+	// module tags since GitHub otherwise picks the latest release.
+	PreviousTagName string `json:"previous_tag_name,omitempty"`
+	// ConfigurationFilePath defaults to .github/release.yml.
+	ConfigurationFilePath string `json:"configuration_file_path,omitempty"`
+}
+
+// ReleaseNotes are release notes generated by GitHub, categorized
+// by pull request labels.
+type ReleaseNotes struct {
+	Name string `json:"name"`
+	Body string `json:"body"`
+}
+
+// GenerateReleaseNotes generates release notes for tag using GitHub
+// generate-notes endpoint, the release itself is not created.
+func (c *Client) GenerateReleaseNotes(ctx context.Context, owner, repo string, params GenerateNotesParams) (*ReleaseNotes, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, repoPath(owner, repo, "releases", "generate-notes"), params)
+	if err != nil {
+		return nil, err
+	}
+	notes := &ReleaseNotes{}
+	if _, err := c.Do(req, notes); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+var ErrRemote = fmt.Errorf("%w: invalid git remote", Error)
+
+// Remote is a GitHub repository referenced by git remote url.
+type Remote struct {
+	Host  string
+	Owner string
+	Repo  string
+}
+
+// ParseRemoteURL parses git remote url in scp-like ssh form
+// "git@github.com:owner/repo.git" or url form using ssh, https, http
+// or git scheme e.g. "https://github.com/owner/repo".
+func ParseRemoteURL(remote string) (Remote, error) {
+	remote = strings.TrimSpace(remote)
+	var host, path string
+	if !strings.Contains(remote, "://") {
+		// scp-like syntax [user@]host:path
+		userHost, p, ok := strings.Cut(remote, ":")
+		if !ok {
+			return Remote{}, fmt.Errorf("%w: %q", ErrRemote, remote)
+		}
+		if i := strings.LastIndex(userHost, "@"); i >= 0 {
+			userHost = userHost[i+1:]
+		}
+		host, path = userHost, p
+	} else {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return Remote{}, fmt.Errorf("%w: %s", ErrRemote, err)
+		}
+		switch u.Scheme {
+		case "ssh", "git+ssh", "https", "http", "git":
+		default:
+			return Remote{}, fmt.Errorf("%w: unsupported scheme %q", ErrRemote, u.Scheme)
+		}
+		host, path = u.Hostname(), u.Path
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	owner, repo, ok := strings.Cut(path, "/")
+	if !ok || host == "" || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return Remote{}, fmt.Errorf("%w: %q", ErrRemote, remote)
+	}
+	return Remote{Host: host, Owner: owner, Repo: repo}, nil
+}
+
+// DetectRemote returns GitHub repository of git repository at dir,
+// using remote "origin" or the first configured remote.
+func DetectRemote(ctx context.Context, dir string) (Remote, error) {
+	name := "origin"
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "remote").Output()
+	if err != nil {
+		return Remote{}, fmt.Errorf("%w: git remote: %s", ErrRemote, err)
+	}
+	remotes := strings.Fields(string(out))
+	if len(remotes) == 0 {
+		return Remote{}, fmt.Errorf("%w: no remotes configured in %s", ErrRemote, dir)
+	}
+	found := false
+	for _, r := range remotes {
+		if r == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		name = remotes[0]
+	}
+
+	out, err = exec.CommandContext(ctx, "git", "-C", dir, "remote", "get-url", name).Output()
+	if err != nil {
+		return Remote{}, fmt.Errorf("%w: git remote get-url %s: %s", ErrRemote, name, err)
+	}
+	return ParseRemoteURL(string(out))
+}
+
+// checkRemoteHost returns error when remote is not hosted on github.com
+// or on host of API at baseURL e.g. GitHub Enterprise Server.
+func checkRemoteHost(remote Remote, baseURL string) error {
+	host := strings.ToLower(remote.Host)
+	if host == "github.com" {
+		return nil
+	}
+	if u, err := url.Parse(baseURL); err == nil && u.Hostname() != "" {
+		api := strings.ToLower(u.Hostname())
+		// API of github.com and GHE.com is served from api. subdomain
+		if host == api || host == strings.TrimPrefix(api, "api.") {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: host %s of %s/%s is not a GitHub host", ErrRemote, remote.Host, remote.Owner, remote.Repo)
+}
+
+// repositoryRoot returns root of git repository of the working directory.
+func repositoryRoot(ctx context.Context) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: git rev-parse --show-toplevel: %s", Error, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrWebhookSignature = fmt.Errorf("%w: invalid webhook signature", Error)
+	ErrWebhookSecret    = fmt.Errorf("%w: webhook secret not configured", Error)
+)
+
+const maxWebhookPayload = 25 << 20 // GitHub caps payloads at 25 MB
+
+// WebhookEvent is a delivered webhook event.
+type WebhookEvent struct {
+	// Type is event type from X-GitHub-Event header e.g. "push", "release".
+	Type string
+	// Action is the action of the event payload e.g. "published", if any.
+	Action string
+	// Delivery is unique delivery id from X-GitHub-Delivery header.
+	Delivery string
+	// Payload is the raw JSON payload.
+	Payload json.RawMessage
+}
+
+// Decode decodes event payload into v.
+func (e *WebhookEvent) Decode(v any) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+// WebhookHandlerFunc handles a webhook event.
+type WebhookHandlerFunc func(ev *WebhookEvent) error
+
+// Webhook is an http.Handler receiving GitHub webhooks. Deliveries are
+// verified with X-Hub-Signature-256 and routed by event type.
+type Webhook struct {
+	secret   []byte
+	mu       sync.RWMutex
+	handlers map[string][]WebhookHandlerFunc
+}
+
+// NewWebhook returns webhook verifying deliveries with secret. Webhook
+// with empty secret rejects all deliveries.
+func NewWebhook(secret string) *Webhook {
+	return &Webhook{
+		secret:   []byte(secret),
+		handlers: make(map[string][]WebhookHandlerFunc),
+	}
+}
+
+// On registers handler for event type, "*" handles all events.
+func (wh *Webhook) On(event string, handler WebhookHandlerFunc) {
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+	wh.handlers[event] = append(wh.handlers[event], handler)
+}
+
+func (wh *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookPayload))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := wh.Verify(payload, r.Header.Get("X-Hub-Signature-256")); err != nil {
+		code := http.StatusUnauthorized
+		if errors.Is(err, ErrWebhookSecret) {
+			code = http.StatusInternalServerError
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	ev := &WebhookEvent{
+		Type:     r.Header.Get("X-GitHub-Event"),
+		Delivery: r.Header.Get("X-GitHub-Delivery"),
+		Payload:  payload,
+	}
+	var meta struct {
+		Action string `json:"action"`
+	}
+	if err := json.Unmarshal(payload, &meta); err != nil {
+		http.Error(w, "invalid payload: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	ev.Action = meta.Action
+
+	wh.mu.RLock()
+	handlers := append(append([]WebhookHandlerFunc{}, wh.handlers[ev.Type]...), wh.handlers["*"]...)
+	wh.mu.RUnlock()
+	for _, handle := range handlers {
+		if err := handle(ev); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Verify checks payload against signature in X-Hub-Signature-256 format
+// "sha256=<hex hmac>". Without secret nothing can be verified and
+// ErrWebhookSecret is returned, unsigned payloads are never accepted.
+func (wh *Webhook) Verify(payload []byte, signature string) error {
+	if len(wh.secret) == 0 {
+		return ErrWebhookSecret
+	}
+	sig, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return ErrWebhookSignature
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return ErrWebhookSignature
+	}
+	mac := hmac.New(sha256.New, wh.secret)
+	mac.Write(payload)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrWebhookSignature
+	}
+	return nil
+}
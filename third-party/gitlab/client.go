@@ -0,0 +1,345 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	defaultHost      = "https://gitlab.com"
+	defaultUserAgent = "happy-sdk-gitlab-addon"
+	defaultRetries   = 3
+	defaultRetryWait = time.Second
+)
+
+var (
+	Error       = errors.New("gitlab")
+	ErrNoToken  = fmt.Errorf("%w: no token available", Error)
+	ErrNotFound = fmt.Errorf("%w: not found", Error)
+	ErrReadBody = fmt.Errorf("%w: failed to read response body", Error)
+)
+
+// ErrorResponse is returned for any API response with a non 2xx status code.
+type ErrorResponse struct {
+	Response *http.Response `json:"-"`
+	// Message is either a string or an object of field errors.
+	Message json.RawMessage `json:"message"`
+	Err     string          `json:"error,omitempty"`
+}
+
+func (e *ErrorResponse) Error() string {
+	msg := e.Err
+	if len(e.Message) > 0 {
+		var s string
+		if err := json.Unmarshal(e.Message, &s); err == nil {
+			msg = s
+		} else {
+			msg = string(e.Message)
+		}
+	}
+	if msg == "" {
+		msg = http.StatusText(e.Response.StatusCode)
+	}
+	return fmt.Sprintf("%s %s: %d %s",
+		e.Response.Request.Method, e.Response.Request.URL.Path,
+		e.Response.StatusCode, msg)
+}
+
+func (e *ErrorResponse) Unwrap() error {
+	if e.Response.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	return Error
+}
+
+// ClientOption configures a Client.
+type ClientOption func(c *Client) error
+
+// WithHost sets the GitLab instance url e.g. "https://gitlab.example.com",
+// API base url is derived from it.
+func WithHost(host string) ClientOption {
+	return func(c *Client) error {
+		base, err := url.Parse(strings.TrimSuffix(host, "/") + "/api/v4/")
+		if err != nil {
+			return fmt.Errorf("%w: invalid host: %s", Error, err)
+		}
+		c.baseURL = base
+		return nil
+	}
+}
+
+// WithHTTPClient sets the underlying http client.
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *Client) error {
+		c.http = hc
+		return nil
+	}
+}
+
+// WithRetries sets how many times a failed idempotent request is retried
+// and the initial wait between attempts, doubled on every attempt.
+func WithRetries(n int, wait time.Duration) ClientOption {
+	return func(c *Client) error {
+		c.retries = n
+		c.retryWait = wait
+		return nil
+	}
+}
+
+// WithJobToken authenticates with CI/CD job token instead of personal,
+// project or group access token. Job tokens can manage releases and
+// packages of the project but can not open merge requests.
+func WithJobToken(token string) ClientOption {
+	return func(c *Client) error {
+		c.token = token
+		c.tokenHeader = "JOB-TOKEN"
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) ClientOption {
+	return func(c *Client) error {
+		c.userAgent = ua
+		return nil
+	}
+}
+
+// Client is an authenticated GitLab REST API client.
+type Client struct {
+	baseURL     *url.URL
+	http        *http.Client
+	token       string
+	tokenHeader string
+	userAgent   string
+	retries     int
+	retryWait   time.Duration
+}
+
+// NewClient returns a new client authenticating with access token.
+// Empty token creates an unauthenticated client.
+func NewClient(token string, opts ...ClientOption) (*Client, error) {
+	c := &Client{
+		http:        &http.Client{Timeout: time.Minute},
+		token:       token,
+		tokenHeader: "PRIVATE-TOKEN",
+		userAgent:   defaultUserAgent,
+		retries:     defaultRetries,
+		retryWait:   defaultRetryWait,
+	}
+	if err := WithHost(defaultHost)(c); err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// NewRequest creates an API request. Relative path is resolved against
+// the base url, body if not nil is JSON encoded.
+func (c *Client) NewRequest(ctx context.Context, method, path string, body any) (*http.Request, error) {
+	u, err := c.baseURL.Parse(strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid path %q: %s", Error, path, err)
+	}
+
+	var r io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to encode request body: %s", Error, err)
+		}
+		r = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), r)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if c.token != "" {
+		req.Header.Set(c.tokenHeader, c.token)
+	}
+	return req, nil
+}
+
+// Do sends the request and decodes JSON response into v when v is not nil.
+// Idempotent requests are retried on network errors and 5xx responses.
+func (c *Client) Do(req *http.Request, v any) (*http.Response, error) {
+	resp, err := c.send(req)
+	if err != nil {
+		return resp, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return resp, err
+	}
+	if v == nil {
+		return resp, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return resp, fmt.Errorf("%w: %s", ErrReadBody, err)
+	}
+	return resp, nil
+}
+
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	attempts := 1
+	if isIdempotent(req.Method) && (req.Body == nil || req.GetBody != nil) {
+		attempts += c.retries
+	}
+
+	wait := c.retryWait
+	for attempt := 1; ; attempt++ {
+		resp, err := c.http.Do(req)
+		if (err == nil && resp.StatusCode < http.StatusInternalServerError) || attempt >= attempts {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	errResp := &ErrorResponse{Response: resp}
+	data, err := io.ReadAll(resp.Body)
+	if err == nil && len(data) > 0 {
+		_ = json.Unmarshal(data, errResp)
+	}
+	return errResp
+}
+
+// ResolveToken returns the access token to authenticate with. The
+// configured token takes precedence, then GITLAB_TOKEN and GL_TOKEN
+// environment variables and finally the token of glab CLI for host.
+func ResolveToken(ctx context.Context, host, configured string) (string, error) {
+	if configured != "" {
+		return configured, nil
+	}
+	for _, key := range []string{"GITLAB_TOKEN", "GL_TOKEN"} {
+		if token := os.Getenv(key); token != "" {
+			return token, nil
+		}
+	}
+	if _, err := exec.LookPath("glab"); err != nil {
+		return "", ErrNoToken
+	}
+	hostname := host
+	if u, err := url.Parse(host); err == nil && u.Host != "" {
+		hostname = u.Host
+	}
+	out, err := exec.CommandContext(ctx, "glab", "config", "get", "token", "--host", hostname).Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: glab config get token: %s", ErrNoToken, err)
+	}
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", ErrNoToken
+	}
+	return token, nil
+}
+
+// User is a GitLab user.
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name,omitempty"`
+	WebURL   string `json:"web_url"`
+}
+
+// Project is a GitLab project.
+type Project struct {
+	ID                int64  `json:"id"`
+	Name              string `json:"name"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	DefaultBranch     string `json:"default_branch"`
+	WebURL            string `json:"web_url"`
+	HTTPURLToRepo     string `json:"http_url_to_repo"`
+	Archived          bool   `json:"archived"`
+}
+
+// CurrentUser returns the authenticated user.
+func (c *Client) CurrentUser(ctx context.Context) (*User, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, "user", nil)
+	if err != nil {
+		return nil, err
+	}
+	user := &User{}
+	if _, err := c.Do(req, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetProject returns project by numeric id or path with namespace
+// e.g. "group/subgroup/project".
+func (c *Client) GetProject(ctx context.Context, project string) (*Project, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, projectPath(project), nil)
+	if err != nil {
+		return nil, err
+	}
+	p := &Project{}
+	if _, err := c.Do(req, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// projectPath returns API path of project, namespaced path is url encoded
+// as a single path segment.
+func projectPath(project string, elem ...string) string {
+	p := "projects/" + url.PathEscape(project)
+	for _, e := range elem {
+		p += "/" + e
+	}
+	return p
+}
@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+
+	"github.com/happy-sdk/happy"
+	"github.com/happy-sdk/happy/sdk/settings"
+)
+
+type Settings struct {
+	Host           settings.String `key:"host" default:"https://gitlab.com" mutation:"once"`
+	Project        settings.String `key:"project" mutation:"once"`
+	Token          settings.String `key:"token" mutation:"once"`
+	ReleasePackage settings.String `key:"release.package" default:"release" mutation:"once"`
+}
+
+func (s Settings) Blueprint() (*settings.Blueprint, error) {
+	b, err := settings.New(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Gitlab is the API provided by the gitlab addon.
+type Gitlab struct {
+	happy.API
+
+	mu      sync.Mutex
+	host    string
+	project string
+	token   string
+	pkg     string
+	client  *Client
+}
+
+func Addon(s Settings) *happy.Addon {
+	addon := happy.NewAddon("gitlab", s)
+
+	api := &Gitlab{}
+	addon.ProvidesAPI(api)
+
+	addon.OnRegister(func(sess *happy.Session) error {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		api.host = setting(sess, "host")
+		api.project = setting(sess, "project")
+		api.token = setting(sess, "token")
+		api.pkg = setting(sess, "release.package")
+
+		// Project setting is only override of the project detected
+		// from CI environment or git remote of the working directory.
+		if api.project != "" {
+			return nil
+		}
+		if p := os.Getenv("CI_PROJECT_PATH"); p != "" {
+			api.project = p
+			return nil
+		}
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil
+		}
+		if remote, err := DetectRemote(sess, wd); err == nil {
+			api.project = remote.Project
+		}
+		return nil
+	})
+
+	return addon
+}
+
+// Project returns the configured project path.
+func (gl *Gitlab) Project() string {
+	gl.mu.Lock()
+	defer gl.mu.Unlock()
+	return gl.project
+}
+
+// Client returns the authenticated API client, creating it on first use.
+// Without access token client authenticates with CI_JOB_TOKEN when
+// running in GitLab CI/CD.
+func (gl *Gitlab) Client(ctx context.Context) (*Client, error) {
+	gl.mu.Lock()
+	defer gl.mu.Unlock()
+	if gl.client != nil {
+		return gl.client, nil
+	}
+
+	var opts []ClientOption
+	if gl.host != "" {
+		opts = append(opts, WithHost(gl.host))
+	}
+	token, err := ResolveToken(ctx, gl.host, gl.token)
+	if errors.Is(err, ErrNoToken) {
+		if job := os.Getenv("CI_JOB_TOKEN"); job != "" {
+			opts = append(opts, WithJobToken(job))
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := NewClient(token, opts...)
+	if err != nil {
+		return nil, err
+	}
+	gl.client = client
+	return client, nil
+}
+
+// Publisher returns publisher creating releases in the configured project.
+func (gl *Gitlab) Publisher(ctx context.Context) (*Publisher, error) {
+	client, err := gl.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gl.mu.Lock()
+	var opts []PublisherOption
+	if gl.pkg != "" {
+		opts = append(opts, WithPackage(gl.pkg))
+	}
+	gl.mu.Unlock()
+	return NewPublisher(client, gl.Project(), opts...), nil
+}
+
+// OpenMergeRequest opens merge request in the configured project,
+// see Client.OpenMergeRequest.
+func (gl *Gitlab) OpenMergeRequest(ctx context.Context, params MergeRequestParams) (*MergeRequest, error) {
+	client, err := gl.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.OpenMergeRequest(ctx, gl.Project(), params)
+}
+
+func setting(sess *happy.Session, key string) string {
+	return sess.Settings().Get("gitlab." + key).Value().String()
+}
@@ -0,0 +1,197 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testClient(t *testing.T, h http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	c, err := NewClient("secret", WithHost(srv.URL), WithRetries(2, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestClientGetProject(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if p := r.URL.EscapedPath(); p != "/api/v4/projects/happy-sdk%2Fsub%2Faddons" {
+			t.Errorf("unexpected path %s", p)
+		}
+		if got := r.Header.Get("PRIVATE-TOKEN"); got != "secret" {
+			t.Errorf("unexpected token header %q", got)
+		}
+		_, _ = w.Write([]byte(`{"id":1,"path_with_namespace":"happy-sdk/sub/addons","default_branch":"main"}`))
+	})
+
+	p, err := c.GetProject(context.Background(), "happy-sdk/sub/addons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.DefaultBranch != "main" {
+		t.Errorf("unexpected default branch %q", p.DefaultBranch)
+	}
+}
+
+func TestClientErrorResponse(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"404 Project Not Found"}`))
+	})
+
+	_, err := c.GetProject(context.Background(), "nope/nope")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "404 Project Not Found") {
+		t.Errorf("unexpected error message %q", err)
+	}
+}
+
+func TestClientJobToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("JOB-TOKEN"); got != "job" {
+			t.Errorf("unexpected job token header %q", got)
+		}
+		if r.Header.Get("PRIVATE-TOKEN") != "" {
+			t.Error("private token header sent with job token")
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+	c, err := NewClient("", WithHost(srv.URL), WithJobToken("job"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListReleases(context.Background(), "g/p", 10); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPublisher(t *testing.T) {
+	asset := filepath.Join(t.TempDir(), "app_linux_amd64.tar.gz")
+	if err := os.WriteFile(asset, []byte("binary"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var created ReleaseParams
+	var uploaded string
+	var link ReleaseLink
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch p := r.URL.EscapedPath(); {
+		case r.Method == http.MethodGet && p == "/api/v4/projects/g%2Fp/releases/pkg%2Fsub%2Fv0.3.1":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"404 Not Found"}`))
+		case r.Method == http.MethodPost && p == "/api/v4/projects/g%2Fp/releases":
+			_ = json.NewDecoder(r.Body).Decode(&created)
+			_, _ = w.Write([]byte(`{"tag_name":"pkg/sub/v0.3.1"}`))
+		case r.Method == http.MethodPut && p == "/api/v4/projects/g%2Fp/packages/generic/release/pkg-sub-v0.3.1/app_linux_amd64.tar.gz":
+			data, _ := io.ReadAll(r.Body)
+			uploaded = string(data)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"message":"201 Created"}`))
+		case r.Method == http.MethodPost && p == "/api/v4/projects/g%2Fp/releases/pkg%2Fsub%2Fv0.3.1/assets/links":
+			_ = json.NewDecoder(r.Body).Decode(&link)
+			_ = json.NewEncoder(w).Encode(link)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, p)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+
+	rels, err := NewPublisher(c, "g/p").Publish(context.Background(), PublishRequest{
+		Tag:    "pkg/sub/v0.3.1",
+		Notes:  "## Changes",
+		Assets: []string{asset},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Name != "pkg/sub/v0.3.1" || created.Description != "## Changes" {
+		t.Errorf("unexpected release params %+v", created)
+	}
+	if uploaded != "binary" {
+		t.Errorf("unexpected uploaded content %q", uploaded)
+	}
+	if link.LinkType != "package" || !strings.HasSuffix(link.URL, "/app_linux_amd64.tar.gz") {
+		t.Errorf("unexpected link %+v", link)
+	}
+	if len(rels) != 1 || len(rels[0].Assets.Links) != 1 {
+		t.Errorf("unexpected releases %+v", rels)
+	}
+}
+
+func TestOpenMergeRequestUpdatesExisting(t *testing.T) {
+	var updated MergeRequestParams
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet:
+			if q := r.URL.Query(); q.Get("source_branch") != "release" || q.Get("state") != "opened" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`[{"iid":7,"state":"opened"}]`))
+		case r.Method == http.MethodPut && r.URL.EscapedPath() == "/api/v4/projects/g%2Fp/merge_requests/7":
+			_ = json.NewDecoder(r.Body).Decode(&updated)
+			_, _ = w.Write([]byte(`{"iid":7,"title":"Release"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	})
+
+	mr, err := c.OpenMergeRequest(context.Background(), "g/p", MergeRequestParams{
+		Title:        "Release",
+		SourceBranch: "release",
+		TargetBranch: "main",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mr.IID != 7 || updated.Title != "Release" || updated.SourceBranch != "" {
+		t.Errorf("unexpected merge request %+v, params %+v", mr, updated)
+	}
+}
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Remote
+		err  bool
+	}{
+		{in: "git@gitlab.com:group/project.git", want: Remote{Host: "gitlab.com", Project: "group/project"}},
+		{in: "https://gitlab.example.com/group/sub/project", want: Remote{Host: "gitlab.example.com", Project: "group/sub/project"}},
+		{in: "ssh://git@gitlab.com:2222/group/project.git", want: Remote{Host: "gitlab.com", Project: "group/project"}},
+		{in: "https://gitlab.com/project", err: true},
+		{in: "ftp://gitlab.com/group/project", err: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRemoteURL(tt.in)
+		if tt.err {
+			if !errors.Is(err, ErrRemote) {
+				t.Errorf("%s: expected ErrRemote, got %v", tt.in, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// MergeRequest is a GitLab merge request.
+type MergeRequest struct {
+	ID           int64    `json:"id"`
+	IID          int      `json:"iid"`
+	State        string   `json:"state"`
+	Title        string   `json:"title"`
+	Description  string   `json:"description"`
+	SourceBranch string   `json:"source_branch"`
+	TargetBranch string   `json:"target_branch"`
+	Draft        bool     `json:"draft"`
+	Labels       []string `json:"labels"`
+	Author       User     `json:"author"`
+	WebURL       string   `json:"web_url"`
+}
+
+// MergeRequestParams are parameters to create or update a merge request.
+type MergeRequestParams struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	// SourceBranch is only used on create.
+	SourceBranch string `json:"source_branch,omitempty"`
+	TargetBranch string `json:"target_branch,omitempty"`
+	// Labels are comma separated label names.
+	Labels             string  `json:"labels,omitempty"`
+	ReviewerIDs        []int64 `json:"reviewer_ids,omitempty"`
+	RemoveSourceBranch bool    `json:"remove_source_branch,omitempty"`
+	Squash             bool    `json:"squash,omitempty"`
+	// StateEvent is close or reopen, only used on update.
+	StateEvent string `json:"state_event,omitempty"`
+}
+
+// CreateMergeRequest opens a merge request in project. Draft merge
+// requests are marked with "Draft:" title prefix.
+func (c *Client) CreateMergeRequest(ctx context.Context, project string, params MergeRequestParams) (*MergeRequest, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, projectPath(project, "merge_requests"), params)
+	if err != nil {
+		return nil, err
+	}
+	mr := &MergeRequest{}
+	if _, err := c.Do(req, mr); err != nil {
+		return nil, err
+	}
+	return mr, nil
+}
+
+// UpdateMergeRequest updates merge request iid.
+func (c *Client) UpdateMergeRequest(ctx context.Context, project string, iid int, params MergeRequestParams) (*MergeRequest, error) {
+	params.SourceBranch = ""
+	req, err := c.NewRequest(ctx, http.MethodPut, projectPath(project, "merge_requests", fmt.Sprint(iid)), params)
+	if err != nil {
+		return nil, err
+	}
+	mr := &MergeRequest{}
+	if _, err := c.Do(req, mr); err != nil {
+		return nil, err
+	}
+	return mr, nil
+}
+
+// FindMergeRequest returns open merge request from source into target
+// branch, nil when there is none.
+func (c *Client) FindMergeRequest(ctx context.Context, project, source, target string) (*MergeRequest, error) {
+	q := url.Values{}
+	q.Set("state", "opened")
+	q.Set("source_branch", source)
+	q.Set("target_branch", target)
+	req, err := c.NewRequest(ctx, http.MethodGet, projectPath(project, "merge_requests")+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var mrs []MergeRequest
+	if _, err := c.Do(req, &mrs); err != nil {
+		return nil, err
+	}
+	if len(mrs) == 0 {
+		return nil, nil
+	}
+	return &mrs[0], nil
+}
+
+// OpenMergeRequest opens merge request or updates title, description and
+// labels of already open merge request from the same source branch,
+// which makes it safe to call on every run of a release flow.
+func (c *Client) OpenMergeRequest(ctx context.Context, project string, params MergeRequestParams) (*MergeRequest, error) {
+	if params.SourceBranch == "" || params.TargetBranch == "" {
+		return nil, fmt.Errorf("%w: source and target branch are required", Error)
+	}
+	mr, err := c.FindMergeRequest(ctx, project, params.SourceBranch, params.TargetBranch)
+	if err != nil {
+		return nil, err
+	}
+	if mr == nil {
+		return c.CreateMergeRequest(ctx, project, params)
+	}
+	return c.UpdateMergeRequest(ctx, project, mr.IID, params)
+}
+
+// DraftTitle returns title marking merge request as draft.
+func DraftTitle(title string) string {
+	if strings.HasPrefix(title, "Draft:") {
+		return title
+	}
+	return "Draft: " + title
+}
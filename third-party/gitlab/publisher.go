@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// PublishRequest describes a single pushed tag to publish as GitLab release.
+type PublishRequest struct {
+	// Tag is the pushed git tag e.g. "v1.2.0" or "pkg/sub/v0.3.1".
+	Tag string
+	// Name of the release, defaults to Tag.
+	Name string
+	// Notes is the changelog of the released module, used as release
+	// description.
+	Notes string
+	// Assets are paths of dist artifacts to attach to the release.
+	Assets []string
+	// Milestones are titles of milestones to associate with the release.
+	Milestones []string
+}
+
+// PublisherOption configures a Publisher.
+type PublisherOption func(p *Publisher)
+
+// WithPackage sets name of the generic package release assets are
+// uploaded to, defaults to "release".
+func WithPackage(name string) PublisherOption {
+	return func(p *Publisher) {
+		p.pkg = name
+	}
+}
+
+// Publisher creates GitLab releases for released tags.
+type Publisher struct {
+	client  *Client
+	project string
+	pkg     string
+}
+
+// NewPublisher returns publisher creating releases in project.
+func NewPublisher(client *Client, project string, opts ...PublisherOption) *Publisher {
+	p := &Publisher{
+		client:  client,
+		project: project,
+		pkg:     "release",
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Publish creates a release for every request. Publishing is idempotent,
+// when release for tag already exists its description is updated and
+// only missing assets are uploaded. Assets are stored in generic package
+// registry and linked to the release.
+func (p *Publisher) Publish(ctx context.Context, reqs ...PublishRequest) ([]*Release, error) {
+	var rels []*Release
+	for _, r := range reqs {
+		rel, err := p.publish(ctx, r)
+		if err != nil {
+			return rels, fmt.Errorf("%w: publish %s: %w", Error, r.Tag, err)
+		}
+		rels = append(rels, rel)
+	}
+	return rels, nil
+}
+
+func (p *Publisher) publish(ctx context.Context, r PublishRequest) (*Release, error) {
+	if r.Tag == "" {
+		return nil, fmt.Errorf("%w: tag is required", Error)
+	}
+	params := ReleaseParams{
+		TagName:     r.Tag,
+		Name:        r.Name,
+		Description: r.Notes,
+		Milestones:  r.Milestones,
+	}
+	if params.Name == "" {
+		params.Name = r.Tag
+	}
+
+	rel, err := p.client.GetRelease(ctx, p.project, r.Tag)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		rel, err = p.client.CreateRelease(ctx, p.project, params)
+	case err == nil:
+		rel, err = p.client.UpdateRelease(ctx, p.project, r.Tag, params)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	linked := make(map[string]bool, len(rel.Assets.Links))
+	for _, l := range rel.Assets.Links {
+		linked[l.Name] = true
+	}
+	version := PackageVersion(r.Tag)
+	for _, path := range r.Assets {
+		name := filepath.Base(path)
+		if linked[name] {
+			continue
+		}
+		u, err := p.client.UploadPackageFile(ctx, p.project, p.pkg, version, path)
+		if err != nil {
+			return nil, err
+		}
+		link, err := p.client.CreateReleaseLink(ctx, p.project, r.Tag, ReleaseLink{
+			Name:            name,
+			URL:             u,
+			DirectAssetPath: "/" + name,
+			LinkType:        "package",
+		})
+		if err != nil {
+			return nil, err
+		}
+		rel.Assets.Links = append(rel.Assets.Links, *link)
+	}
+	return rel, nil
+}
@@ -0,0 +1,168 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Release is a GitLab release.
+type Release struct {
+	TagName         string    `json:"tag_name"`
+	Name            string    `json:"name"`
+	Description     string    `json:"description"`
+	CreatedAt       time.Time `json:"created_at"`
+	ReleasedAt      time.Time `json:"released_at"`
+	UpcomingRelease bool      `json:"upcoming_release"`
+	Assets          struct {
+		Links []ReleaseLink `json:"links"`
+	} `json:"assets"`
+	Links struct {
+		Self string `json:"self"`
+	} `json:"_links"`
+}
+
+// ReleaseLink is an asset link of a release.
+type ReleaseLink struct {
+	ID              int64  `json:"id,omitempty"`
+	Name            string `json:"name"`
+	URL             string `json:"url"`
+	DirectAssetPath string `json:"direct_asset_path,omitempty"`
+	DirectAssetURL  string `json:"direct_asset_url,omitempty"`
+	// LinkType is one of other, runbook, image or package.
+	LinkType string `json:"link_type,omitempty"`
+}
+
+// ReleaseParams are parameters to create or update a release.
+type ReleaseParams struct {
+	TagName     string `json:"tag_name,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Ref is commit to create tag from when tag does not exist.
+	// Only used on create.
+	Ref        string   `json:"ref,omitempty"`
+	Milestones []string `json:"milestones,omitempty"`
+	// ReleasedAt in the future marks release as upcoming release.
+	ReleasedAt *time.Time `json:"released_at,omitempty"`
+}
+
+// CreateRelease creates a release of project.
+func (c *Client) CreateRelease(ctx context.Context, project string, params ReleaseParams) (*Release, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, projectPath(project, "releases"), params)
+	if err != nil {
+		return nil, err
+	}
+	rel := &Release{}
+	if _, err := c.Do(req, rel); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// UpdateRelease updates release of tag.
+func (c *Client) UpdateRelease(ctx context.Context, project, tag string, params ReleaseParams) (*Release, error) {
+	params.TagName = ""
+	req, err := c.NewRequest(ctx, http.MethodPut, projectPath(project, "releases", url.PathEscape(tag)), params)
+	if err != nil {
+		return nil, err
+	}
+	rel := &Release{}
+	if _, err := c.Do(req, rel); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// GetRelease returns release of tag.
+func (c *Client) GetRelease(ctx context.Context, project, tag string) (*Release, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, projectPath(project, "releases", url.PathEscape(tag)), nil)
+	if err != nil {
+		return nil, err
+	}
+	rel := &Release{}
+	if _, err := c.Do(req, rel); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// ListReleases returns up to limit most recent releases of project.
+func (c *Client) ListReleases(ctx context.Context, project string, limit int) ([]Release, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	req, err := c.NewRequest(ctx, http.MethodGet, projectPath(project, "releases")+fmt.Sprintf("?per_page=%d", limit), nil)
+	if err != nil {
+		return nil, err
+	}
+	var rels []Release
+	if _, err := c.Do(req, &rels); err != nil {
+		return nil, err
+	}
+	return rels, nil
+}
+
+// CreateReleaseLink adds asset link to release of tag.
+func (c *Client) CreateReleaseLink(ctx context.Context, project, tag string, link ReleaseLink) (*ReleaseLink, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, projectPath(project, "releases", url.PathEscape(tag), "assets", "links"), link)
+	if err != nil {
+		return nil, err
+	}
+	out := &ReleaseLink{}
+	if _, err := c.Do(req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UploadPackageFile uploads file at path to generic package registry of
+// project as package name and version and returns download url of the
+// file. Version may not contain "/", see PackageVersion.
+func (c *Client) UploadPackageFile(ctx context.Context, project, name, version, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	p := projectPath(project, "packages", "generic",
+		url.PathEscape(name), url.PathEscape(version), url.PathEscape(filepath.Base(path)))
+	req, err := c.NewRequest(ctx, http.MethodPut, p, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Body = io.NopCloser(f)
+	req.ContentLength = stat.Size()
+	// reopening body makes upload retryable
+	req.GetBody = func() (io.ReadCloser, error) {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(f), nil
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if _, err := c.Do(req, nil); err != nil {
+		return "", err
+	}
+	return req.URL.String(), nil
+}
+
+// PackageVersion returns generic package version for release tag, slashes
+// of module prefix e.g. "pkg/sub/v0.3.1" are not allowed in versions.
+func PackageVersion(tag string) string {
+	return strings.ReplaceAll(tag, "/", "-")
+}
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+var ErrRemote = fmt.Errorf("%w: invalid git remote", Error)
+
+// Remote is a GitLab project referenced by git remote url.
+type Remote struct {
+	Host string
+	// Project is path with namespace, which may include subgroups
+	// e.g. "group/subgroup/project".
+	Project string
+}
+
+// ParseRemoteURL parses git remote url in scp-like ssh form
+// "git@gitlab.com:group/project.git" or url form using ssh, https, http
+// or git scheme e.g. "https://gitlab.com/group/subgroup/project".
+func ParseRemoteURL(remote string) (Remote, error) {
+	remote = strings.TrimSpace(remote)
+	var host, path string
+	if !strings.Contains(remote, "://") {
+		// scp-like syntax [user@]host:path
+		userHost, p, ok := strings.Cut(remote, ":")
+		if !ok {
+			return Remote{}, fmt.Errorf("%w: %q", ErrRemote, remote)
+		}
+		if i := strings.LastIndex(userHost, "@"); i >= 0 {
+			userHost = userHost[i+1:]
+		}
+		host, path = userHost, p
+	} else {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return Remote{}, fmt.Errorf("%w: %s", ErrRemote, err)
+		}
+		switch u.Scheme {
+		case "ssh", "git+ssh", "https", "http", "git":
+		default:
+			return Remote{}, fmt.Errorf("%w: unsupported scheme %q", ErrRemote, u.Scheme)
+		}
+		host, path = u.Hostname(), u.Path
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || !strings.Contains(path, "/") || strings.Contains(path, "//") {
+		return Remote{}, fmt.Errorf("%w: %q", ErrRemote, remote)
+	}
+	return Remote{Host: host, Project: path}, nil
+}
+
+// DetectRemote returns GitLab project of git repository at dir,
+// using remote "origin" or the first configured remote.
+func DetectRemote(ctx context.Context, dir string) (Remote, error) {
+	name := "origin"
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "remote").Output()
+	if err != nil {
+		return Remote{}, fmt.Errorf("%w: git remote: %s", ErrRemote, err)
+	}
+	remotes := strings.Fields(string(out))
+	if len(remotes) == 0 {
+		return Remote{}, fmt.Errorf("%w: no remotes configured in %s", ErrRemote, dir)
+	}
+	found := false
+	for _, r := range remotes {
+		if r == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		name = remotes[0]
+	}
+
+	out, err = exec.CommandContext(ctx, "git", "-C", dir, "remote", "get-url", name).Output()
+	if err != nil {
+		return Remote{}, fmt.Errorf("%w: git remote get-url %s: %s", ErrRemote, name, err)
+	}
+	return ParseRemoteURL(string(out))
+}
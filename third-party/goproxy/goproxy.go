@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package goproxy
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/happy-sdk/happy"
+	"github.com/happy-sdk/happy/sdk/settings"
+)
+
+type Settings struct {
+	URL           settings.String `key:"url" mutation:"once"`
+	Mode          settings.String `key:"mode" default:"fetch" mutation:"once"`
+	Username      settings.String `key:"username" mutation:"once"`
+	Password      settings.String `key:"password" mutation:"once"`
+	Token         settings.String `key:"token" mutation:"once"`
+	VerifyTimeout settings.Int    `key:"verify.timeout" default:"120" mutation:"once"`
+}
+
+func (s Settings) Blueprint() (*settings.Blueprint, error) {
+	b, err := settings.New(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Goproxy is the API provided by the goproxy addon.
+type Goproxy struct {
+	happy.API
+
+	mu    sync.Mutex
+	proxy *Proxy
+}
+
+func Addon(s Settings) *happy.Addon {
+	addon := happy.NewAddon("goproxy", s)
+
+	api := &Goproxy{}
+	addon.ProvidesAPI(api)
+
+	addon.OnRegister(func(sess *happy.Session) error {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		opts := []ProxyOption{
+			WithBasicAuth(setting(sess, "username"), setting(sess, "password")),
+			WithBearerToken(setting(sess, "token")),
+		}
+		if secs, err := strconv.Atoi(setting(sess, "verify.timeout")); err == nil && secs > 0 {
+			opts = append(opts, WithVerify(time.Duration(secs)*time.Second, 5*time.Second))
+		}
+		proxy, err := NewProxy(setting(sess, "url"), setting(sess, "mode"), opts...)
+		if err != nil {
+			return err
+		}
+		api.proxy = proxy
+		return nil
+	})
+
+	return addon
+}
+
+// Proxy returns the configured private module proxy.
+func (g *Goproxy) Proxy() *Proxy {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.proxy
+}
+
+func setting(sess *happy.Session, key string) string {
+	return sess.Settings().Get("goproxy." + key).Value().String()
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package goproxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPublishFetch(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, _ := r.BasicAuth(); u != "ci" || p != "pw" {
+			t.Errorf("unexpected credentials %q %q", u, p)
+		}
+		// proxy becomes available after first check
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.URL.Path {
+		case "/github.com/!happy-!s!d!k/app/@v/v1.2.0.info":
+			_, _ = w.Write([]byte(`{"Version":"v1.2.0","Time":"2024-01-02T03:04:05Z"}`))
+		case "/github.com/!happy-!s!d!k/app/@v/v1.2.0.mod", "/github.com/!happy-!s!d!k/app/@v/v1.2.0.zip":
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	p, err := NewProxy(srv.URL+"/", ModeFetch, WithBasicAuth("ci", "pw"), WithVerify(time.Second, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := p.Publish(context.Background(), "github.com/Happy-SDK/app", "v1.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Version != "v1.2.0" || requests.Load() != 4 {
+		t.Errorf("unexpected info %+v after %d requests", info, requests.Load())
+	}
+}
+
+func TestVerifyUnavailable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	p, err := NewProxy(srv.URL, ModeFetch, WithVerify(20*time.Millisecond, 5*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Verify(context.Background(), "example.com/m", "v0.1.0"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable, got %v", err)
+	}
+	if _, err := NewProxy(srv.URL, "push"); !errors.Is(err, Error) {
+		t.Errorf("expected unknown mode error, got %v", err)
+	}
+}
@@ -0,0 +1,256 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package goproxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	Error          = errors.New("goproxy")
+	ErrUnavailable = fmt.Errorf("%w: module version not available", Error)
+	ErrUpload      = fmt.Errorf("%w: upload failed", Error)
+)
+
+// Publish modes.
+const (
+	// ModeFetch asks caching proxy (Athens, Artifactory or Nexus remote
+	// repository) to fetch released version from its origin.
+	ModeFetch = "fetch"
+	// ModeUpload uploads module files to hosted repository.
+	ModeUpload = "upload"
+)
+
+// ProxyOption configures a Proxy.
+type ProxyOption func(p *Proxy)
+
+// WithBasicAuth authenticates requests with username and password.
+func WithBasicAuth(username, password string) ProxyOption {
+	return func(p *Proxy) {
+		p.username, p.password = username, password
+	}
+}
+
+// WithBearerToken authenticates requests with bearer token.
+func WithBearerToken(token string) ProxyOption {
+	return func(p *Proxy) {
+		p.token = token
+	}
+}
+
+// WithVerify sets how long Publish waits for released version
+// to become available and the interval between checks.
+func WithVerify(timeout, interval time.Duration) ProxyOption {
+	return func(p *Proxy) {
+		p.timeout, p.interval = timeout, interval
+	}
+}
+
+// Proxy publishes released modules to private module proxy.
+type Proxy struct {
+	url      string
+	mode     string
+	username string
+	password string
+	token    string
+	timeout  time.Duration
+	interval time.Duration
+	http     *http.Client
+}
+
+// NewProxy returns proxy serving GOPROXY protocol at url
+// e.g. "https://artifactory.example.com/artifactory/api/go/go-local".
+func NewProxy(url, mode string, opts ...ProxyOption) (*Proxy, error) {
+	switch mode {
+	case ModeFetch, ModeUpload:
+	default:
+		return nil, fmt.Errorf("%w: unknown mode %q", Error, mode)
+	}
+	p := &Proxy{
+		url:      strings.TrimSuffix(url, "/"),
+		mode:     mode,
+		timeout:  2 * time.Minute,
+		interval: 5 * time.Second,
+		http:     &http.Client{Timeout: 5 * time.Minute},
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p, nil
+}
+
+// Info is module version info served by proxy.
+type Info struct {
+	Version string    `json:"Version"`
+	Time    time.Time `json:"Time"`
+}
+
+// Publish makes module at version available in proxy and verifies it
+// can be downloaded. Module files are uploaded in upload mode, caching
+// proxy fetches them on first request in fetch mode.
+func (p *Proxy) Publish(ctx context.Context, module, version string) (*Info, error) {
+	if p.mode == ModeUpload {
+		files, err := download(ctx, module, version)
+		if err != nil {
+			return nil, err
+		}
+		// info last, so file based proxy lists version only once its
+		// zip and go.mod are in place
+		for _, ext := range []string{"zip", "mod", "info"} {
+			if err := p.upload(ctx, module, version, ext, files[ext]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return p.Verify(ctx, module, version)
+}
+
+// Verify waits until proxy serves info, go.mod and zip of module version.
+func (p *Proxy) Verify(ctx context.Context, module, version string) (*Info, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	var lastErr error
+	for {
+		info, err := p.Info(ctx, module, version)
+		if err == nil {
+			if err = p.head(ctx, module, version, "mod"); err == nil {
+				if err = p.head(ctx, module, version, "zip"); err == nil {
+					return info, nil
+				}
+			}
+		}
+		lastErr = err
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %s@%s: %w", ErrUnavailable, module, version, lastErr)
+		case <-time.After(p.interval):
+		}
+	}
+}
+
+// Info returns version info of module version.
+func (p *Proxy) Info(ctx context.Context, module, version string) (*Info, error) {
+	req, err := p.newRequest(ctx, http.MethodGet, p.fileURL(module, version, "info"), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("info: %s", resp.Status)
+	}
+	info := &Info{}
+	if err := json.NewDecoder(resp.Body).Decode(info); err != nil {
+		return nil, fmt.Errorf("info: %s", err)
+	}
+	if info.Version != version {
+		return nil, fmt.Errorf("info: proxy resolved %s", info.Version)
+	}
+	return info, nil
+}
+
+func (p *Proxy) head(ctx context.Context, module, version, ext string) error {
+	req, err := p.newRequest(ctx, http.MethodHead, p.fileURL(module, version, ext), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", ext, resp.Status)
+	}
+	return nil
+}
+
+func (p *Proxy) upload(ctx context.Context, module, version, ext, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	req, err := p.newRequest(ctx, http.MethodPut, p.fileURL(module, version, ext), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %s", ErrUpload, ext, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%w: %s: %s %s", ErrUpload, ext, resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+func (p *Proxy) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case p.token != "":
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	case p.username != "":
+		req.SetBasicAuth(p.username, p.password)
+	}
+	return req, nil
+}
+
+func (p *Proxy) fileURL(module, version, ext string) string {
+	return p.url + "/" + escapePath(module) + "/@v/" + escapePath(version) + "." + ext
+}
+
+// download returns paths of info, mod and zip files of module version
+// fetched directly from origin by go command.
+func download(ctx context.Context, module, version string) (map[string]string, error) {
+	cmd := exec.CommandContext(ctx, "go", "mod", "download", "-json", module+"@"+version)
+	cmd.Env = append(os.Environ(), "GOPROXY=direct", "GOFLAGS=-mod=mod", "GONOSUMDB="+module)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	var dl struct {
+		Info  string
+		GoMod string
+		Zip   string
+		Error string
+	}
+	_ = json.Unmarshal(out, &dl)
+	if err != nil || dl.Error != "" {
+		return nil, fmt.Errorf("%w: go mod download %s@%s: %s %s", ErrUpload, module, version, dl.Error, strings.TrimSpace(stderr.String()))
+	}
+	return map[string]string{"info": dl.Info, "mod": dl.GoMod, "zip": dl.Zip}, nil
+}
+
+// escapePath escapes module path or version for proxy urls, upper case
+// letters are replaced with "!" followed by the lower case letter.
+func escapePath(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package helm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ChartFile is name of chart metadata file.
+const ChartFile = "Chart.yaml"
+
+// Chart is metadata of a chart relevant to releasing it.
+type Chart struct {
+	Name       string
+	Version    string
+	AppVersion string
+}
+
+// ReadChart reads metadata of chart in dir.
+func ReadChart(dir string) (Chart, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ChartFile))
+	if err != nil {
+		return Chart{}, fmt.Errorf("%w: %w", Error, err)
+	}
+	var c Chart
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := topLevelKey(line)
+		if !ok {
+			continue
+		}
+		switch key {
+		case "name":
+			c.Name = value
+		case "version":
+			c.Version = value
+		case "appVersion":
+			c.AppVersion = value
+		}
+	}
+	if c.Name == "" || c.Version == "" {
+		return Chart{}, fmt.Errorf("%w: %s has no name or version", ErrChart, filepath.Join(dir, ChartFile))
+	}
+	return c, nil
+}
+
+// SetVersion sets chart version and appVersion of chart in dir, appVersion
+// is added when chart does not have it. Only the two top level keys are
+// rewritten so that comments and formatting of Chart.yaml are preserved.
+func SetVersion(dir, version, appVersion string) error {
+	path := filepath.Join(dir, ChartFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", Error, err)
+	}
+	lines := strings.Split(string(data), "\n")
+	var hasVersion, hasAppVersion bool
+	for i, line := range lines {
+		key, _, ok := topLevelKey(line)
+		if !ok {
+			continue
+		}
+		switch key {
+		case "version":
+			lines[i], hasVersion = "version: "+version, true
+		case "appVersion":
+			lines[i], hasAppVersion = "appVersion: "+strconv.Quote(appVersion), true
+		}
+	}
+	if !hasVersion {
+		return fmt.Errorf("%w: %s has no version", ErrChart, path)
+	}
+	if !hasAppVersion {
+		if n := len(lines); n > 0 && lines[n-1] == "" {
+			lines = append(lines[:n-1], "appVersion: "+strconv.Quote(appVersion), "")
+		} else {
+			lines = append(lines, "appVersion: "+strconv.Quote(appVersion))
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", Error, err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), info.Mode()); err != nil {
+		return fmt.Errorf("%w: %w", Error, err)
+	}
+	return nil
+}
+
+// ChartVersion returns chart version of released version, charts
+// require SemVer 2 without the "v" prefix used in git tags.
+func ChartVersion(version string) string {
+	return strings.TrimPrefix(version, "v")
+}
+
+// topLevelKey returns key and unquoted scalar value of unindented
+// YAML mapping line.
+func topLevelKey(line string) (key, value string, ok bool) {
+	if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' {
+		return "", "", false
+	}
+	key, value, ok = strings.Cut(line, ":")
+	if !ok {
+		return "", "", false
+	}
+	value = strings.TrimSpace(value)
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	if uq, err := strconv.Unquote(value); err == nil {
+		value = uq
+	} else {
+		value = strings.Trim(value, "'")
+	}
+	return strings.TrimSpace(key), value, true
+}
@@ -0,0 +1,3 @@
+module github.com/happy-sdk/addons/third-party/helm
+
+go 1.21.5
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package helm
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/happy-sdk/happy"
+	"github.com/happy-sdk/happy/sdk/settings"
+)
+
+type Settings struct {
+	Chart      settings.String `key:"chart" default:"chart" mutation:"once"`
+	Repository settings.String `key:"repository" mutation:"once"`
+	Username   settings.String `key:"username" mutation:"once"`
+	Password   settings.String `key:"password" mutation:"once"`
+}
+
+func (s Settings) Blueprint() (*settings.Blueprint, error) {
+	b, err := settings.New(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Helm is the API provided by the helm addon.
+type Helm struct {
+	happy.API
+
+	mu        sync.Mutex
+	publisher *Publisher
+}
+
+func Addon(s Settings) *happy.Addon {
+	addon := happy.NewAddon("helm", s)
+
+	api := &Helm{}
+	addon.ProvidesAPI(api)
+
+	addon.OnRegister(func(sess *happy.Session) error {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		repository := setting(sess, "repository")
+		username, password := setting(sess, "username"), setting(sess, "password")
+		// charts pushed to ghcr.io use the workflow token
+		if strings.HasPrefix(repository, "oci://ghcr.io/") {
+			if password == "" {
+				password = os.Getenv("GITHUB_TOKEN")
+			}
+			if username == "" {
+				username = os.Getenv("GITHUB_ACTOR")
+			}
+		}
+		api.publisher = NewPublisher(NewCLI(), setting(sess, "chart"), repository, username, password)
+		return nil
+	})
+
+	return addon
+}
+
+// Publisher returns publisher of the configured chart.
+func (h *Helm) Publisher() *Publisher {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.publisher
+}
+
+func setting(sess *happy.Session, key string) string {
+	return sess.Settings().Get("helm." + key).Value().String()
+}
@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package helm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const chartYAML = `apiVersion: v2
+name: app
+description: A Helm chart # of the app
+version: 0.1.0
+appVersion: "0.1.0"
+dependencies:
+  - name: redis
+    version: 17.0.0
+`
+
+// fakeChart returns publisher of chart written to temp dir using fake
+// helm logging its arguments to returned log file.
+func fakeChart(t *testing.T, repository string) (*Publisher, string) {
+	t.Helper()
+	dir := t.TempDir()
+	chart := filepath.Join(dir, "chart")
+	if err := os.Mkdir(chart, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(chart, ChartFile), []byte(chartYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	log := filepath.Join(dir, "calls")
+	script := `#!/bin/sh
+echo "$@" >> ` + log + `
+case "$1" in
+package) echo "chart" > "$4/app-1.2.0.tgz"; echo "Successfully packaged chart and saved it to: $4/app-1.2.0.tgz" ;;
+push) echo "Pushed: ghcr.io/o/charts/app:1.2.0" >&2; echo "Digest: sha256:$(printf %064d 7)" >&2 ;;
+esac
+`
+	bin := filepath.Join(dir, "helm")
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return NewPublisher(&CLI{bin: bin}, chart, repository, "ci", "secret"), log
+}
+
+func TestSetVersion(t *testing.T) {
+	p, _ := fakeChart(t, "")
+	if err := SetVersion(p.dir, "1.2.0", "v1.2.0"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(p.dir, ChartFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(strings.Replace(chartYAML, "version: 0.1.0", "version: 1.2.0", 1), `"0.1.0"`, `"v1.2.0"`, 1)
+	if string(data) != want {
+		t.Errorf("unexpected Chart.yaml\n%s", data)
+	}
+	c, err := ReadChart(p.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c != (Chart{Name: "app", Version: "1.2.0", AppVersion: "v1.2.0"}) {
+		t.Errorf("unexpected chart %+v", c)
+	}
+}
+
+func TestPublishOCI(t *testing.T) {
+	p, log := fakeChart(t, "oci://ghcr.io/o/charts/")
+	pub, err := p.Publish(context.Background(), "v1.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub.Ref != "oci://ghcr.io/o/charts/app:1.2.0" || !strings.HasPrefix(pub.Digest, "sha256:") {
+		t.Errorf("unexpected published %+v", pub)
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(calls) != 3 ||
+		calls[1] != "registry login ghcr.io --username ci --password-stdin" ||
+		!strings.HasPrefix(calls[2], "push ") || !strings.HasSuffix(calls[2], "/app-1.2.0.tgz oci://ghcr.io/o/charts") {
+		t.Errorf("unexpected calls\n%s", data)
+	}
+	// packaged archive is removed with its temporary directory
+	archive := strings.Fields(calls[2])[1]
+	if _, err := os.Stat(filepath.Dir(archive)); !os.IsNotExist(err) || pub.Archive != "app-1.2.0.tgz" {
+		t.Errorf("expected %s removed, got %v", archive, err)
+	}
+}
+
+func TestPublishChartRepository(t *testing.T) {
+	var uploaded string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, pw, _ := r.BasicAuth(); r.URL.Path != "/api/charts" || u != "ci" || pw != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if uploaded != "" {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error":"file already exists"}`))
+			return
+		}
+		uploaded = string(body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	p, _ := fakeChart(t, srv.URL)
+	pub, err := p.Publish(context.Background(), "v1.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uploaded != "chart\n" || pub.Ref != srv.URL {
+		t.Errorf("unexpected upload %q of %+v", uploaded, pub)
+	}
+	if _, err := p.Publish(context.Background(), "v1.2.0"); !errors.Is(err, ErrPush) || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected ErrPush, got %v", err)
+	}
+}
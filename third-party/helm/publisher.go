@@ -0,0 +1,202 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	Error    = errors.New("helm")
+	ErrHelm  = fmt.Errorf("%w: helm", Error)
+	ErrChart = fmt.Errorf("%w: invalid chart", Error)
+	ErrPush  = fmt.Errorf("%w: push failed", Error)
+)
+
+var (
+	packagedRe = regexp.MustCompile(`saved it to: (\S+)`)
+	pushedRe   = regexp.MustCompile(`Digest: (sha256:[0-9a-f]{64})`)
+)
+
+// CLI runs helm CLI.
+type CLI struct {
+	bin string
+}
+
+// NewCLI returns helm CLI runner.
+func NewCLI() *CLI {
+	return &CLI{bin: "helm"}
+}
+
+// Package packages chart in dir into dest and returns path of the archive.
+func (h *CLI) Package(ctx context.Context, dir, dest string) (string, error) {
+	out, err := h.run(ctx, nil, "package", dir, "--destination", dest)
+	if err != nil {
+		return "", err
+	}
+	m := packagedRe.FindSubmatch(out)
+	if m == nil {
+		return "", fmt.Errorf("%w: no archive in package output of %s", ErrHelm, dir)
+	}
+	return string(m[1]), nil
+}
+
+// Login logs in to OCI registry with username and password read from stdin.
+func (h *CLI) Login(ctx context.Context, registry, username, password string) error {
+	_, err := h.run(ctx, strings.NewReader(password), "registry", "login", registry, "--username", username, "--password-stdin")
+	return err
+}
+
+// Push pushes packaged chart to OCI repository e.g.
+// "oci://ghcr.io/owner/charts" and returns digest of pushed chart.
+func (h *CLI) Push(ctx context.Context, archive, repository string) (string, error) {
+	out, err := h.run(ctx, nil, "push", archive, repository)
+	if err != nil {
+		return "", err
+	}
+	m := pushedRe.FindSubmatch(out)
+	if m == nil {
+		return "", nil
+	}
+	return string(m[1]), nil
+}
+
+func (h *CLI) run(ctx context.Context, stdin *strings.Reader, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, h.bin, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	// helm push prints the digest to stderr
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return out.Bytes(), fmt.Errorf("%w: %s %s: %s: %s", ErrHelm, h.bin, args[0], err, strings.TrimSpace(out.String()))
+	}
+	return out.Bytes(), nil
+}
+
+// Published is a published chart.
+type Published struct {
+	Chart Chart
+	// Archive is file name of packaged chart e.g. "app-1.2.0.tgz", the
+	// archive itself is removed once pushed.
+	Archive string
+	// Ref is chart reference e.g. "oci://ghcr.io/owner/charts/app:1.2.0"
+	// or chart repository URL.
+	Ref string
+	// Digest of pushed chart, set only for OCI repositories.
+	Digest string
+}
+
+// Publisher bumps, packages and pushes a chart to OCI registry or to
+// chart repository accepting ChartMuseum compatible uploads.
+type Publisher struct {
+	helm       *CLI
+	http       *http.Client
+	dir        string
+	repository string
+	username   string
+	password   string
+}
+
+// NewPublisher returns publisher of chart in dir to repository.
+// Repositories with "oci://" scheme are pushed with helm push, http(s)
+// repositories receive the archive with POST to /api/charts.
+func NewPublisher(h *CLI, dir, repository, username, password string) *Publisher {
+	return &Publisher{
+		helm:       h,
+		http:       &http.Client{Timeout: 5 * time.Minute},
+		dir:        dir,
+		repository: strings.TrimSuffix(repository, "/"),
+		username:   username,
+		password:   password,
+	}
+}
+
+// Publish sets chart version and appVersion to released version, packages
+// the chart and pushes it to the repository.
+func (p *Publisher) Publish(ctx context.Context, version string) (*Published, error) {
+	if p.repository == "" {
+		return nil, fmt.Errorf("%w: no chart repository configured", Error)
+	}
+	if err := SetVersion(p.dir, ChartVersion(version), version); err != nil {
+		return nil, err
+	}
+	chart, err := ReadChart(p.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	dest, err := os.MkdirTemp("", "helm-"+chart.Name+"-")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", Error, err)
+	}
+	defer os.RemoveAll(dest)
+	archive, err := p.helm.Package(ctx, p.dir, dest)
+	if err != nil {
+		return nil, err
+	}
+	pub := &Published{Chart: chart, Archive: filepath.Base(archive)}
+
+	if strings.HasPrefix(p.repository, "oci://") {
+		if p.password != "" {
+			host, _, _ := strings.Cut(strings.TrimPrefix(p.repository, "oci://"), "/")
+			if err := p.helm.Login(ctx, host, p.username, p.password); err != nil {
+				return nil, err
+			}
+		}
+		digest, err := p.helm.Push(ctx, archive, p.repository)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrPush, err)
+		}
+		pub.Ref = p.repository + "/" + chart.Name + ":" + chart.Version
+		pub.Digest = digest
+		return pub, nil
+	}
+
+	if err := p.upload(ctx, archive); err != nil {
+		return nil, err
+	}
+	pub.Ref = p.repository
+	return pub, nil
+}
+
+// upload uploads archive to ChartMuseum compatible chart repository.
+func (p *Publisher) upload(ctx context.Context, archive string) error {
+	data, err := os.ReadFile(archive)
+	if err != nil {
+		return fmt.Errorf("%w: %w", Error, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.repository+"/api/charts", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %w", Error, err)
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+	if p.username != "" || p.password != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrPush, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%w: %s: %s: %s", ErrPush, filepath.Base(archive), resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
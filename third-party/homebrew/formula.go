@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package homebrew
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// Artifact is a release archive of a single platform.
+type Artifact struct {
+	// OS is "darwin" or "linux".
+	OS string
+	// Arch is "amd64" or "arm64".
+	Arch   string
+	URL    string
+	SHA256 string
+}
+
+// Formula describes a Homebrew formula installing prebuilt binaries.
+type Formula struct {
+	// Name is formula name e.g. "happy", file Formula/happy.rb in tap.
+	Name     string
+	Desc     string
+	Homepage string
+	// Version without "v" prefix.
+	Version   string
+	License   string
+	Artifacts []Artifact
+	// Binaries installed from archive, Name when empty.
+	Binaries []string
+	// Test is formula test block body, runs "<name> --version" when empty.
+	Test string
+}
+
+// Path returns path of formula in tap repository.
+func (f Formula) Path() string {
+	return "Formula/" + f.Name + ".rb"
+}
+
+// Render renders formula Ruby source.
+func (f Formula) Render() ([]byte, error) {
+	if f.Name == "" || f.Version == "" || len(f.Artifacts) == 0 {
+		return nil, fmt.Errorf("%w: formula requires name, version and artifacts", Error)
+	}
+	data := struct {
+		Formula
+		Class    string
+		Platform map[string][]Artifact
+	}{Formula: f, Class: className(f.Name), Platform: map[string][]Artifact{}}
+	if len(data.Binaries) == 0 {
+		data.Binaries = []string{f.Name}
+	}
+	if data.Test == "" {
+		data.Test = `system "#{bin}/` + data.Binaries[0] + `", "--version"`
+	}
+	for _, a := range f.Artifacts {
+		switch a.OS {
+		case "darwin":
+			data.Platform["macos"] = append(data.Platform["macos"], a)
+		case "linux":
+			data.Platform["linux"] = append(data.Platform["linux"], a)
+		default:
+			return nil, fmt.Errorf("%w: unsupported os %q", Error, a.OS)
+		}
+		if a.Arch != "amd64" && a.Arch != "arm64" {
+			return nil, fmt.Errorf("%w: unsupported arch %q", Error, a.Arch)
+		}
+	}
+	for _, as := range data.Platform {
+		sort.Slice(as, func(i, j int) bool { return as[i].Arch < as[j].Arch })
+	}
+
+	var buf bytes.Buffer
+	if err := formulaTmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("%w: %w", Error, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// className returns Ruby class name of formula name e.g. "happy-cli"
+// becomes "HappyCli".
+func className(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '-' || r == '_' || r == '.' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var formulaTmpl = template.Must(template.New("formula").Funcs(template.FuncMap{
+	"cpu": func(arch string) string {
+		if arch == "arm64" {
+			return "arm?"
+		}
+		return "intel?"
+	},
+}).Parse(`# typed: false
+# frozen_string_literal: true
+
+class {{ .Class }} < Formula
+  desc {{ printf "%q" .Desc }}
+  homepage {{ printf "%q" .Homepage }}
+  version {{ printf "%q" .Version }}
+{{- with .License }}
+  license {{ printf "%q" . }}
+{{- end }}
+{{- range $os, $artifacts := .Platform }}
+
+  on_{{ $os }} do
+{{- range $artifacts }}
+    if Hardware::CPU.{{ cpu .Arch }}
+      url {{ printf "%q" .URL }}
+      sha256 {{ printf "%q" .SHA256 }}
+    end
+{{- end }}
+  end
+{{- end }}
+
+  def install
+{{- range .Binaries }}
+    bin.install {{ printf "%q" . }}
+{{- end }}
+  end
+
+  test do
+    {{ .Test }}
+  end
+end
+`))
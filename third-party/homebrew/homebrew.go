@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package homebrew
+
+import (
+	"os"
+	"sync"
+
+	"github.com/happy-sdk/happy"
+	"github.com/happy-sdk/happy/sdk/settings"
+)
+
+type Settings struct {
+	Tap         settings.String `key:"tap" mutation:"once"`
+	Branch      settings.String `key:"branch" default:"main" mutation:"once"`
+	Token       settings.String `key:"token" mutation:"once"`
+	PullRequest settings.Bool   `key:"pull_request" default:"false" mutation:"once"`
+	AuthorName  settings.String `key:"author.name" default:"github-actions[bot]" mutation:"once"`
+	AuthorEmail settings.String `key:"author.email" default:"41898282+github-actions[bot]@users.noreply.github.com" mutation:"once"`
+}
+
+func (s Settings) Blueprint() (*settings.Blueprint, error) {
+	b, err := settings.New(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Homebrew is the API provided by the homebrew addon.
+type Homebrew struct {
+	happy.API
+
+	mu  sync.Mutex
+	tap *Tap
+}
+
+func Addon(s Settings) *happy.Addon {
+	addon := happy.NewAddon("homebrew", s)
+
+	api := &Homebrew{}
+	addon.ProvidesAPI(api)
+
+	addon.OnRegister(func(sess *happy.Session) error {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		token := setting(sess, "token")
+		// GITHUB_TOKEN of a workflow can not push to other repositories,
+		// tap token is checked first
+		for _, env := range []string{"HOMEBREW_TAP_GITHUB_TOKEN", "GITHUB_TOKEN"} {
+			if token != "" {
+				break
+			}
+			token = os.Getenv(env)
+		}
+		api.tap = NewTap(
+			setting(sess, "tap"),
+			setting(sess, "branch"),
+			token,
+			Author{Name: setting(sess, "author.name"), Email: setting(sess, "author.email")},
+			setting(sess, "pull_request") == "true",
+		)
+		return nil
+	})
+
+	return addon
+}
+
+// Tap returns the configured tap repository.
+func (h *Homebrew) Tap() *Tap {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.tap
+}
+
+func setting(sess *happy.Session, key string) string {
+	return sess.Settings().Get("homebrew." + key).Value().String()
+}
@@ -0,0 +1,219 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package homebrew
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testFormula = Formula{
+	Name:     "happy-cli",
+	Desc:     "Happy command line",
+	Homepage: "https://github.com/happy-sdk/happy",
+	Version:  "1.2.0",
+	License:  "Apache-2.0",
+	Artifacts: []Artifact{
+		{OS: "darwin", Arch: "amd64", URL: "https://example.com/happy_darwin_amd64.tar.gz", SHA256: "aa"},
+		{OS: "darwin", Arch: "arm64", URL: "https://example.com/happy_darwin_arm64.tar.gz", SHA256: "bb"},
+		{OS: "linux", Arch: "amd64", URL: "https://example.com/happy_linux_amd64.tar.gz", SHA256: "cc"},
+	},
+	Binaries: []string{"happy"},
+}
+
+func TestRender(t *testing.T) {
+	source, err := testFormula.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# typed: false
+# frozen_string_literal: true
+
+class HappyCli < Formula
+  desc "Happy command line"
+  homepage "https://github.com/happy-sdk/happy"
+  version "1.2.0"
+  license "Apache-2.0"
+
+  on_linux do
+    if Hardware::CPU.intel?
+      url "https://example.com/happy_linux_amd64.tar.gz"
+      sha256 "cc"
+    end
+  end
+
+  on_macos do
+    if Hardware::CPU.intel?
+      url "https://example.com/happy_darwin_amd64.tar.gz"
+      sha256 "aa"
+    end
+    if Hardware::CPU.arm?
+      url "https://example.com/happy_darwin_arm64.tar.gz"
+      sha256 "bb"
+    end
+  end
+
+  def install
+    bin.install "happy"
+  end
+
+  test do
+    system "#{bin}/happy", "--version"
+  end
+end
+`
+	if string(source) != want {
+		t.Errorf("unexpected formula\n%s", source)
+	}
+}
+
+// testTap returns tap pushing to local bare repository with main branch.
+func testTap(t *testing.T, pr bool) (*Tap, string) {
+	t.Helper()
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "homebrew-tap.git")
+	work := filepath.Join(dir, "work")
+	for _, args := range [][]string{
+		{"init", "--bare", "--initial-branch", "main", remote},
+		{"clone", remote, work},
+		{"-C", work, "-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "--allow-empty", "-m", "init"},
+		{"-C", work, "push", "origin", "HEAD:refs/heads/main"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Skipf("git %s: %s: %s", args[0], err, out)
+		}
+	}
+	tap := NewTap("happy-sdk/homebrew-tap", "main", "", Author{Name: "bot", Email: "bot@example.com"}, pr)
+	tap.remote = remote
+	return tap, remote
+}
+
+func gitOut(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %s: %s", args[0], err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestPublishPush(t *testing.T) {
+	tap, remote := testTap(t, false)
+	pub, err := tap.Publish(context.Background(), testFormula)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub.Branch != "main" || pub.Commit != gitOut(t, remote, "rev-parse", "main") {
+		t.Errorf("unexpected published %+v", pub)
+	}
+	if got := gitOut(t, remote, "log", "-1", "--format=%an %s", "main"); got != "bot happy-cli 1.2.0" {
+		t.Errorf("unexpected commit %q", got)
+	}
+	if got := gitOut(t, remote, "show", "main:Formula/happy-cli.rb"); !strings.Contains(got, "class HappyCli < Formula") {
+		t.Errorf("unexpected formula %q", got)
+	}
+
+	// unchanged formula is not committed again
+	pub, err = tap.Publish(context.Background(), testFormula)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub.Commit != "" {
+		t.Errorf("expected no commit, got %+v", pub)
+	}
+}
+
+func TestPublishPullRequest(t *testing.T) {
+	tap, remote := testTap(t, true)
+	var opened bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			if r.URL.Path != "/repos/happy-sdk/homebrew-tap/pulls" || r.URL.Query().Get("head") != "happy-sdk:happy-cli-1.2.0" {
+				t.Errorf("unexpected request %s", r.URL)
+			}
+			_, _ = w.Write([]byte(`[{"html_url":"https://github.com/happy-sdk/homebrew-tap/pull/7"}]`))
+			return
+		}
+		var pr map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&pr); err != nil || r.URL.Path != "/repos/happy-sdk/homebrew-tap/pulls" {
+			t.Errorf("unexpected request %s %v", r.URL.Path, err)
+		}
+		if pr["head"] != "happy-cli-1.2.0" || pr["base"] != "main" {
+			t.Errorf("unexpected pull request %v", pr)
+		}
+		if opened {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"message":"Validation Failed","errors":[{"message":"A pull request already exists for happy-sdk:happy-cli-1.2.0."}]}`))
+			return
+		}
+		opened = true
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"html_url":"https://github.com/happy-sdk/homebrew-tap/pull/7"}`))
+	}))
+	defer srv.Close()
+	tap.api = srv.URL + "/"
+
+	pub, err := tap.Publish(context.Background(), testFormula)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub.PullRequest != "https://github.com/happy-sdk/homebrew-tap/pull/7" ||
+		pub.Commit != gitOut(t, remote, "rev-parse", "happy-cli-1.2.0") {
+		t.Errorf("unexpected published %+v", pub)
+	}
+	if got := gitOut(t, remote, "log", "-1", "--format=%s", "main"); got != "init" {
+		t.Errorf("expected main untouched, got %q", got)
+	}
+
+	// publishing again replaces branch and reuses open pull request
+	f := testFormula
+	f.Desc = "Happy command line interface"
+	pub, err = tap.Publish(context.Background(), f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub.PullRequest != "https://github.com/happy-sdk/homebrew-tap/pull/7" ||
+		pub.Commit != gitOut(t, remote, "rev-parse", "happy-cli-1.2.0") {
+		t.Errorf("unexpected republished %+v", pub)
+	}
+}
+
+func TestPublishTokenNotInArgs(t *testing.T) {
+	tap, _ := testTap(t, false)
+	tap.token = "secret"
+	dir := t.TempDir()
+	log := filepath.Join(dir, "git.log")
+	git, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip(err)
+	}
+	tap.git = filepath.Join(dir, "git")
+	script := "#!/bin/sh\necho \"$* | $GIT_CONFIG_KEY_0\" >> " + log + "\nexec " + git + " \"$@\"\n"
+	if err := os.WriteFile(tap.git, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GIT_CONFIG_COUNT", "")
+
+	if _, err := tap.Publish(context.Background(), testFormula); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		args, key, _ := strings.Cut(line, " | ")
+		if strings.Contains(args, "extraHeader") || key != "http.extraHeader" {
+			t.Errorf("unexpected git call %q", line)
+		}
+	}
+}
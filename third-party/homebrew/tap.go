@@ -0,0 +1,247 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package homebrew
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	Error  = errors.New("homebrew")
+	ErrGit = fmt.Errorf("%w: git", Error)
+	ErrPR  = fmt.Errorf("%w: pull request", Error)
+)
+
+// Published is result of publishing formula to tap.
+type Published struct {
+	// Commit is hash of formula commit, empty when formula was unchanged.
+	Commit string
+	// Branch formula commit was pushed to.
+	Branch string
+	// PullRequest is URL of opened pull request.
+	PullRequest string
+}
+
+// Author is git author of formula commits.
+type Author struct {
+	Name  string
+	Email string
+}
+
+// Tap publishes formulae to a tap repository hosted on GitHub.
+type Tap struct {
+	repo   string
+	branch string
+	token  string
+	author Author
+	// pr opens pull request instead of pushing to branch.
+	pr bool
+
+	git    string
+	remote string
+	api    string
+	http   *http.Client
+}
+
+// NewTap returns tap of GitHub repository repo e.g. "owner/homebrew-tap"
+// with formulae committed to branch by author. Pushes and pull requests
+// are authenticated with token.
+func NewTap(repo, branch, token string, author Author, pullRequest bool) *Tap {
+	return &Tap{
+		repo:   repo,
+		branch: branch,
+		token:  token,
+		author: author,
+		pr:     pullRequest,
+		git:    "git",
+		remote: "https://github.com/" + repo + ".git",
+		api:    "https://api.github.com/",
+		http:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Publish clones the tap, writes rendered formula and commits it. The
+// commit is pushed to the tap branch, or to a new branch with pull request
+// opened against the tap branch when tap publishes with pull requests.
+func (t *Tap) Publish(ctx context.Context, f Formula) (*Published, error) {
+	if t.repo == "" {
+		return nil, fmt.Errorf("%w: no tap repository configured", Error)
+	}
+	source, err := f.Render()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "homebrew-tap-")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", Error, err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := t.run(ctx, "", "clone", "--depth", "1", "--branch", t.branch, t.remote, dir); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, filepath.FromSlash(f.Path()))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("%w: %w", Error, err)
+	}
+	if err := os.WriteFile(path, source, 0o644); err != nil {
+		return nil, fmt.Errorf("%w: %w", Error, err)
+	}
+	if _, err := t.run(ctx, dir, "add", f.Path()); err != nil {
+		return nil, err
+	}
+	status, err := t.run(ctx, dir, "status", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	pub := &Published{Branch: t.branch}
+	if len(bytes.TrimSpace(status)) == 0 {
+		return pub, nil
+	}
+
+	if t.pr {
+		pub.Branch = f.Name + "-" + f.Version
+		if _, err := t.run(ctx, dir, "checkout", "-b", pub.Branch); err != nil {
+			return nil, err
+		}
+	}
+	title := fmt.Sprintf("%s %s", f.Name, f.Version)
+	if _, err := t.run(ctx, dir, "commit", "-m", title); err != nil {
+		return nil, err
+	}
+	head, err := t.run(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	pub.Commit = strings.TrimSpace(string(head))
+	ref := "HEAD:refs/heads/" + pub.Branch
+	if t.pr {
+		// branch of previous attempt is replaced, tap branch never is
+		ref = "+" + ref
+	}
+	if _, err := t.run(ctx, dir, "push", "origin", ref); err != nil {
+		return nil, err
+	}
+
+	if t.pr {
+		url, err := t.openPullRequest(ctx, pub.Branch, title)
+		if err != nil {
+			return nil, err
+		}
+		pub.PullRequest = url
+	}
+	return pub, nil
+}
+
+// openPullRequest opens pull request of head against tap branch, or
+// returns already open pull request of head.
+func (t *Tap) openPullRequest(ctx context.Context, head, title string) (string, error) {
+	body, err := json.Marshal(map[string]string{
+		"title": title,
+		"head":  head,
+		"base":  t.branch,
+		"body":  "Formula update published by release of " + title + ".",
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrPR, err)
+	}
+	var pr struct {
+		HTMLURL string `json:"html_url"`
+	}
+	status, err := t.request(ctx, http.MethodPost, "repos/"+t.repo+"/pulls", body, &pr)
+	if status == http.StatusUnprocessableEntity {
+		// publishing again updated branch of pull request already open
+		if existing, ferr := t.findPullRequest(ctx, head); ferr == nil && existing != "" {
+			return existing, nil
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return pr.HTMLURL, nil
+}
+
+// findPullRequest returns URL of open pull request of head, empty when
+// there is none.
+func (t *Tap) findPullRequest(ctx context.Context, head string) (string, error) {
+	owner, _, _ := strings.Cut(t.repo, "/")
+	var open []struct {
+		HTMLURL string `json:"html_url"`
+	}
+	path := "repos/" + t.repo + "/pulls?state=open&head=" + url.QueryEscape(owner+":"+head)
+	if _, err := t.request(ctx, http.MethodGet, path, nil, &open); err != nil || len(open) == 0 {
+		return "", err
+	}
+	return open[0].HTMLURL, nil
+}
+
+// request sends GitHub API request and decodes successful response into v.
+// Returns response status code, also with error of unsuccessful response.
+func (t *Tap) request(ctx context.Context, method, path string, body []byte, v any) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, t.api+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrPR, err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	resp, err := t.http.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrPR, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return resp.StatusCode, fmt.Errorf("%w: %s: %s", ErrPR, resp.Status, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return resp.StatusCode, fmt.Errorf("%w: %w", ErrPR, err)
+	}
+	return resp.StatusCode, nil
+}
+
+// run runs git in dir as tap author. Token is passed as http header
+// through environment, so that it never appears in remote URLs, error
+// messages or process list.
+func (t *Tap) run(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	name := args[0]
+	cmd := exec.CommandContext(ctx, t.git, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+t.author.Name, "GIT_AUTHOR_EMAIL="+t.author.Email,
+		"GIT_COMMITTER_NAME="+t.author.Name, "GIT_COMMITTER_EMAIL="+t.author.Email,
+	)
+	if t.token != "" {
+		auth := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + t.token))
+		// keep configuration already passed through environment
+		n, _ := strconv.Atoi(os.Getenv("GIT_CONFIG_COUNT"))
+		cmd.Env = append(cmd.Env,
+			"GIT_CONFIG_COUNT="+strconv.Itoa(n+1),
+			"GIT_CONFIG_KEY_"+strconv.Itoa(n)+"=http.extraHeader",
+			"GIT_CONFIG_VALUE_"+strconv.Itoa(n)+"=AUTHORIZATION: basic "+auth,
+		)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return out, fmt.Errorf("%w: %s: %s: %s", ErrGit, name, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+var (
+	Error         = errors.New("matrix")
+	ErrNoRoom     = fmt.Errorf("%w: no homeserver or room configured", Error)
+	ErrTemplate   = fmt.Errorf("%w: invalid template", Error)
+	ErrPostFailed = fmt.Errorf("%w: send message failed", Error)
+)
+
+// DefaultTemplate renders plain text body of release announcement.
+const DefaultTemplate = `{{ .Project }} {{ .Version }} released{{ if .URL }}: {{ .URL }}{{ end }}
+{{ .Changelog }}`
+
+// DefaultHTMLTemplate renders formatted body of release announcement.
+const DefaultHTMLTemplate = `<strong>{{ .Project }} {{ .Version }}</strong> released{{ if .URL }}: <a href="{{ .URL }}">{{ .URL }}</a>{{ end }}
+{{ if .Changelog }}<pre>{{ .Changelog }}</pre>{{ end }}`
+
+// Release is the release manifest data announcements are rendered from.
+type Release struct {
+	Project   string
+	Version   string
+	Tags      []string
+	URL       string
+	Changelog string
+}
+
+// Message is m.room.message event content.
+type Message struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format,omitempty"`
+	FormattedBody string `json:"formatted_body,omitempty"`
+}
+
+// Client sends release announcements to Matrix rooms through
+// client-server API of homeserver.
+type Client struct {
+	homeserver string
+	token      string
+	text       *texttemplate.Template
+	html       *template.Template
+	http       *http.Client
+	// retryDelay is delay before second send attempt, growing linearly.
+	retryDelay time.Duration
+}
+
+// NewClient returns client of homeserver e.g. "https://matrix.org"
+// authenticated with access token. Body and formatted body are rendered
+// from text and html templates, defaults are used when empty.
+func NewClient(homeserver, token, textTmpl, htmlTmpl string) (*Client, error) {
+	if textTmpl == "" {
+		textTmpl = DefaultTemplate
+	}
+	if htmlTmpl == "" {
+		htmlTmpl = DefaultHTMLTemplate
+	}
+	text, err := texttemplate.New("matrix").Parse(textTmpl)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrTemplate, err)
+	}
+	html, err := template.New("matrix").Parse(htmlTmpl)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrTemplate, err)
+	}
+	return &Client{
+		homeserver: strings.TrimSuffix(homeserver, "/"),
+		token:      token,
+		text:       text,
+		html:       html,
+		http:       &http.Client{Timeout: 30 * time.Second},
+		retryDelay: time.Second,
+	}, nil
+}
+
+// Message renders announcement of r as notice, so bots in the room
+// do not react to it.
+func (c *Client) Message(r Release) (Message, error) {
+	var body, formatted strings.Builder
+	if err := c.text.Execute(&body, r); err != nil {
+		return Message{}, fmt.Errorf("%w: %s", ErrTemplate, err)
+	}
+	if err := c.html.Execute(&formatted, r); err != nil {
+		return Message{}, fmt.Errorf("%w: %s", ErrTemplate, err)
+	}
+	return Message{
+		MsgType:       "m.notice",
+		Body:          strings.TrimSpace(body.String()),
+		Format:        "org.matrix.custom.html",
+		FormattedBody: strings.TrimSpace(formatted.String()),
+	}, nil
+}
+
+// Announce sends announcement of r to every room, given by room id
+// "!id:server" or alias "#alias:server".
+func (c *Client) Announce(ctx context.Context, r Release, rooms ...string) error {
+	if c.homeserver == "" || len(rooms) == 0 {
+		return ErrNoRoom
+	}
+	msg, err := c.Message(r)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, room := range rooms {
+		if err := c.Send(ctx, room, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", room, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sendAttempts is number of attempts to send message when homeserver is
+// unavailable or rate limits the client.
+const sendAttempts = 3
+
+// Send sends msg to room, resolving room alias first.
+func (c *Client) Send(ctx context.Context, room string, msg Message) error {
+	roomID := room
+	if strings.HasPrefix(room, "#") {
+		var out struct {
+			RoomID string `json:"room_id"`
+		}
+		if _, err := c.do(ctx, http.MethodGet, "directory/room/"+url.PathEscape(room), nil, &out); err != nil {
+			return err
+		}
+		roomID = out.RoomID
+	}
+	// retries reuse transaction id, so homeserver which received the
+	// event before failing does not post it again
+	txn := fmt.Sprintf("happy-%d", time.Now().UnixNano())
+	p := "rooms/" + url.PathEscape(roomID) + "/send/m.room.message/" + txn
+	for attempt := 1; ; attempt++ {
+		status, err := c.do(ctx, http.MethodPut, p, msg, nil)
+		retry := status == 0 || status == http.StatusTooManyRequests || status >= 500
+		if err == nil || !retry || attempt == sendAttempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(c.retryDelay * time.Duration(attempt)):
+		}
+	}
+}
+
+// do sends API request and decodes response into out. Returns response
+// status code, zero when no response was received.
+func (c *Client) do(ctx context.Context, method, path string, in, out any) (int, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.homeserver+"/_matrix/client/v3/"+path, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrPostFailed, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrPostFailed, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var merr struct {
+			ErrCode string `json:"errcode"`
+			Error   string `json:"error"`
+		}
+		_ = json.Unmarshal(data, &merr)
+		return resp.StatusCode, fmt.Errorf("%w: %d %s %s", ErrPostFailed, resp.StatusCode, merr.ErrCode, merr.Error)
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return resp.StatusCode, fmt.Errorf("%w: %s", ErrPostFailed, err)
+		}
+	}
+	return resp.StatusCode, nil
+}
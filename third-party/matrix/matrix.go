@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package matrix
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/happy-sdk/happy"
+	"github.com/happy-sdk/happy/sdk/settings"
+)
+
+type Settings struct {
+	Homeserver   settings.String `key:"homeserver" default:"https://matrix.org" mutation:"once"`
+	Token        settings.String `key:"token" mutation:"once"`
+	Rooms        settings.String `key:"rooms" mutation:"once"`
+	Template     settings.String `key:"template" mutation:"once"`
+	HTMLTemplate settings.String `key:"html_template" mutation:"once"`
+}
+
+func (s Settings) Blueprint() (*settings.Blueprint, error) {
+	b, err := settings.New(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Matrix is the API provided by the matrix addon.
+type Matrix struct {
+	happy.API
+
+	mu     sync.Mutex
+	rooms  []string
+	client *Client
+}
+
+func Addon(s Settings) *happy.Addon {
+	addon := happy.NewAddon("matrix", s)
+
+	api := &Matrix{}
+	addon.ProvidesAPI(api)
+
+	addon.OnRegister(func(sess *happy.Session) error {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		for _, room := range strings.Split(setting(sess, "rooms"), ",") {
+			if room = strings.TrimSpace(room); room != "" {
+				api.rooms = append(api.rooms, room)
+			}
+		}
+		client, err := NewClient(
+			setting(sess, "homeserver"),
+			setting(sess, "token"),
+			setting(sess, "template"),
+			setting(sess, "html_template"),
+		)
+		if err != nil {
+			return err
+		}
+		api.client = client
+		return nil
+	})
+
+	return addon
+}
+
+// Announce sends announcement of r to the configured rooms.
+func (m *Matrix) Announce(ctx context.Context, r Release) error {
+	m.mu.Lock()
+	client, rooms := m.client, m.rooms
+	m.mu.Unlock()
+	if client == nil {
+		return ErrNoRoom
+	}
+	return client.Announce(ctx, r, rooms...)
+}
+
+func setting(sess *happy.Session, key string) string {
+	return sess.Settings().Get("matrix." + key).Value().String()
+}
@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAnnounce(t *testing.T) {
+	var sent []string
+	var got Message
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+		switch p := r.URL.EscapedPath(); {
+		case r.Method == http.MethodGet && p == "/_matrix/client/v3/directory/room/%23releases:example.org":
+			_, _ = w.Write([]byte(`{"room_id":"!abc:example.org"}`))
+		case r.Method == http.MethodPut && strings.HasPrefix(p, "/_matrix/client/v3/rooms/"):
+			room, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/_matrix/client/v3/rooms/"), "/")
+			sent = append(sent, room)
+			_ = json.NewDecoder(r.Body).Decode(&got)
+			_, _ = w.Write([]byte(`{"event_id":"$1"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, p)
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errcode":"M_NOT_FOUND","error":"no"}`))
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Announce(context.Background(), Release{
+		Project:   "happy",
+		Version:   "v1.2.0",
+		Changelog: "- fix <b>",
+	}, "#releases:example.org", "!def:example.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 2 || sent[0] != "!abc:example.org" || sent[1] != "!def:example.org" {
+		t.Errorf("unexpected rooms %v", sent)
+	}
+	if got.MsgType != "m.notice" || !strings.HasPrefix(got.Body, "happy v1.2.0 released") {
+		t.Errorf("unexpected message %+v", got)
+	}
+	if !strings.Contains(got.FormattedBody, "<pre>- fix &lt;b&gt;</pre>") {
+		t.Errorf("changelog not escaped in formatted body %q", got.FormattedBody)
+	}
+}
+
+func TestSendError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errcode":"M_FORBIDDEN","error":"not in room"}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Announce(context.Background(), Release{Project: "happy"}, "!x:example.org")
+	if !errors.Is(err, ErrPostFailed) || !strings.Contains(err.Error(), "M_FORBIDDEN") {
+		t.Errorf("expected M_FORBIDDEN error, got %v", err)
+	}
+	if err := c.Announce(context.Background(), Release{}); !errors.Is(err, ErrNoRoom) {
+		t.Errorf("expected ErrNoRoom, got %v", err)
+	}
+}
+
+func TestSendRetryReusesTransaction(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if len(paths) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"event_id":"$1"}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.retryDelay = 0
+	if err := c.Send(context.Background(), "!x:example.org", Message{MsgType: "m.notice", Body: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 || paths[0] != paths[1] {
+		t.Errorf("expected retry with same transaction, got %v", paths)
+	}
+}
@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+var (
+	Error     = errors.New("registry")
+	ErrDocker = fmt.Errorf("%w: docker", Error)
+	ErrAuth   = fmt.Errorf("%w: authentication failed", Error)
+)
+
+// Authentication modes.
+const (
+	// AuthToken logs in with username and token.
+	AuthToken = "token"
+	// AuthKeychain relies on docker credential helpers and stored logins.
+	AuthKeychain = "keychain"
+	// AuthOIDC logs in with registry token exchanged for OIDC token of
+	// the CI job.
+	AuthOIDC = "oidc"
+)
+
+var digestRe = regexp.MustCompile(`digest: (sha256:[0-9a-f]{64})`)
+
+// Image is an image built by docker stage.
+type Image struct {
+	// Ref is local image reference e.g. "app:v1.2.0-arm64".
+	Ref string
+	// Platform e.g. "linux/arm64", empty for single platform image.
+	Platform string
+}
+
+// PushRequest describes images of a single release to push.
+type PushRequest struct {
+	// Tags are tags to push e.g. "v1.2.0", "1.2", "latest".
+	Tags   []string
+	Images []Image
+}
+
+// Pushed is a pushed image reference with its manifest digest.
+type Pushed struct {
+	Ref    string
+	Digest string
+}
+
+// Docker pushes images with docker CLI. Multi platform manifests
+// are created with buildx imagetools.
+type Docker struct {
+	bin string
+}
+
+// NewDocker returns docker CLI runner.
+func NewDocker() *Docker {
+	return &Docker{bin: "docker"}
+}
+
+// Login logs in to registry with username and password read from stdin.
+func (d *Docker) Login(ctx context.Context, registry, username, password string) error {
+	if _, err := d.run(ctx, strings.NewReader(password), "login", registry, "--username", username, "--password-stdin"); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrAuth, registry, err)
+	}
+	return nil
+}
+
+// Tag tags local image src as dst.
+func (d *Docker) Tag(ctx context.Context, src, dst string) error {
+	_, err := d.run(ctx, nil, "tag", src, dst)
+	return err
+}
+
+// Push pushes ref and returns digest of pushed manifest.
+func (d *Docker) Push(ctx context.Context, ref string) (string, error) {
+	out, err := d.run(ctx, nil, "push", ref)
+	if err != nil {
+		return "", err
+	}
+	return parseDigest(ref, out)
+}
+
+// CreateManifest creates multi platform manifest list target from pushed
+// platform images and returns its digest.
+func (d *Docker) CreateManifest(ctx context.Context, target string, sources ...string) (string, error) {
+	args := append([]string{"buildx", "imagetools", "create", "--tag", target}, sources...)
+	if _, err := d.run(ctx, nil, args...); err != nil {
+		return "", err
+	}
+	out, err := d.run(ctx, nil, "buildx", "imagetools", "inspect", target, "--format", "{{.Manifest.Digest}}")
+	if err != nil {
+		return "", err
+	}
+	digest := strings.TrimSpace(string(out))
+	if !strings.HasPrefix(digest, "sha256:") {
+		return "", fmt.Errorf("%w: no digest of %s in %q", ErrDocker, target, digest)
+	}
+	return digest, nil
+}
+
+func (d *Docker) run(ctx context.Context, stdin *strings.Reader, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, d.bin, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return out, fmt.Errorf("%w: %s %s: %s: %s", ErrDocker, d.bin, args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// parseDigest returns manifest digest from docker push output.
+func parseDigest(ref string, out []byte) (string, error) {
+	m := digestRe.FindSubmatch(out)
+	if m == nil {
+		return "", fmt.Errorf("%w: no digest in push output of %s", ErrDocker, ref)
+	}
+	return string(m[1]), nil
+}
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// tokenExchangeGrant is RFC 8693 token exchange grant type.
+const tokenExchangeGrant = "urn:ietf:params:oauth:grant-type:token-exchange"
+
+// OIDCCredentials returns registry credentials of the GitHub Actions job.
+// Registries do not accept the raw ID token as password, so ID token for
+// audience is exchanged for registry access token at exchangeURL.
+func OIDCCredentials(ctx context.Context, audience, exchangeURL, provider string) (username, token string, err error) {
+	if exchangeURL == "" {
+		return "", "", fmt.Errorf("%w: oidc auth requires token exchange url, registries do not accept raw ID tokens", ErrAuth)
+	}
+	idToken, err := ActionsIDToken(ctx, audience)
+	if err != nil {
+		return "", "", err
+	}
+	return ExchangeIDToken(ctx, exchangeURL, provider, idToken)
+}
+
+// ExchangeIDToken exchanges OIDC ID token for registry access token at
+// RFC 8693 token exchange endpoint. Provider is sent as provider_name
+// when not empty, for endpoints trusting several identity providers.
+// Username is empty unless endpoint returns one.
+func ExchangeIDToken(ctx context.Context, exchangeURL, provider, idToken string) (username, token string, err error) {
+	form := url.Values{
+		"grant_type":         {tokenExchangeGrant},
+		"subject_token":      {idToken},
+		"subject_token_type": {"urn:ietf:params:oauth:token-type:id_token"},
+	}
+	if provider != "" {
+		form.Set("provider_name", provider)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, exchangeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: token exchange: %s", ErrAuth, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("%w: token exchange: %s", ErrAuth, resp.Status)
+	}
+	var out struct {
+		AccessToken string `json:"access_token"`
+		Username    string `json:"username"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil || out.AccessToken == "" {
+		return "", "", fmt.Errorf("%w: token exchange: invalid response", ErrAuth)
+	}
+	return out.Username, out.AccessToken, nil
+}
+
+// ActionsIDToken requests OIDC token for audience from GitHub Actions.
+// Workflow job needs "id-token: write" permission.
+func ActionsIDToken(ctx context.Context, audience string) (string, error) {
+	reqURL := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL")
+	reqToken := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+	if reqURL == "" || reqToken == "" {
+		return "", fmt.Errorf("%w: OIDC token is not available, missing id-token permission or not running in GitHub Actions", ErrAuth)
+	}
+	if audience != "" {
+		reqURL += "&audience=" + url.QueryEscape(audience)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+reqToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: OIDC token: %s", ErrAuth, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: OIDC token: %s", ErrAuth, resp.Status)
+	}
+	var out struct {
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil || out.Value == "" {
+		return "", fmt.Errorf("%w: OIDC token: invalid response", ErrAuth)
+	}
+	return out.Value, nil
+}
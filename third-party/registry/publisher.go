@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Publisher pushes release images to repository of a registry.
+type Publisher struct {
+	docker *Docker
+	// repository is image repository including registry host
+	// e.g. "ghcr.io/happy-sdk/app".
+	repository string
+}
+
+// NewPublisher returns publisher pushing to repository e.g.
+// "ghcr.io/happy-sdk/app" or "docker.io/happysdk/app".
+func NewPublisher(docker *Docker, repository string) *Publisher {
+	return &Publisher{docker: docker, repository: repository}
+}
+
+// Push tags and pushes images of r under every tag. Single platform
+// image is pushed as is, images of multiple platforms are pushed under
+// platform suffixed tags and combined into a manifest list for every tag.
+// Returns digests of pushed tags for the release manifest.
+func (p *Publisher) Push(ctx context.Context, r PushRequest) ([]Pushed, error) {
+	if len(r.Tags) == 0 || len(r.Images) == 0 {
+		return nil, fmt.Errorf("%w: push requires tags and images", Error)
+	}
+
+	if len(r.Images) == 1 && r.Images[0].Platform == "" {
+		var pushed []Pushed
+		for _, tag := range r.Tags {
+			ref := p.repository + ":" + tag
+			if err := p.docker.Tag(ctx, r.Images[0].Ref, ref); err != nil {
+				return pushed, err
+			}
+			digest, err := p.docker.Push(ctx, ref)
+			if err != nil {
+				return pushed, err
+			}
+			pushed = append(pushed, Pushed{Ref: ref, Digest: digest})
+		}
+		return pushed, nil
+	}
+
+	var sources []string
+	for _, img := range r.Images {
+		if img.Platform == "" {
+			return nil, fmt.Errorf("%w: platform of %s is required for multi platform push", Error, img.Ref)
+		}
+		ref := p.repository + ":" + r.Tags[0] + "-" + platformSuffix(img.Platform)
+		if err := p.docker.Tag(ctx, img.Ref, ref); err != nil {
+			return nil, err
+		}
+		digest, err := p.docker.Push(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, p.repository+"@"+digest)
+	}
+
+	var pushed []Pushed
+	for _, tag := range r.Tags {
+		ref := p.repository + ":" + tag
+		digest, err := p.docker.CreateManifest(ctx, ref, sources...)
+		if err != nil {
+			return pushed, err
+		}
+		pushed = append(pushed, Pushed{Ref: ref, Digest: digest})
+	}
+	return pushed, nil
+}
+
+// platformSuffix returns tag suffix of platform e.g. "linux-arm64-v8"
+// for "linux/arm64/v8".
+func platformSuffix(platform string) string {
+	return strings.ReplaceAll(platform, "/", "-")
+}
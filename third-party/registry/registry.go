@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/happy-sdk/happy"
+	"github.com/happy-sdk/happy/sdk/settings"
+)
+
+type Settings struct {
+	Registry     settings.String `key:"registry" default:"ghcr.io" mutation:"once"`
+	Repository   settings.String `key:"repository" mutation:"once"`
+	Auth         settings.String `key:"auth" default:"token" mutation:"once"`
+	Username     settings.String `key:"username" mutation:"once"`
+	Token        settings.String `key:"token" mutation:"once"`
+	OIDCAudience settings.String `key:"oidc.audience" mutation:"once"`
+	OIDCExchange settings.String `key:"oidc.exchange_url" mutation:"once"`
+	OIDCProvider settings.String `key:"oidc.provider" mutation:"once"`
+}
+
+func (s Settings) Blueprint() (*settings.Blueprint, error) {
+	b, err := settings.New(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Registry is the API provided by the registry addon.
+type Registry struct {
+	happy.API
+
+	mu         sync.Mutex
+	registry   string
+	repository string
+	auth       string
+	username   string
+	token      string
+	audience   string
+	exchange   string
+	provider   string
+	docker     *Docker
+}
+
+func Addon(s Settings) *happy.Addon {
+	addon := happy.NewAddon("registry", s)
+
+	api := &Registry{docker: NewDocker()}
+	addon.ProvidesAPI(api)
+
+	addon.OnRegister(func(sess *happy.Session) error {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		api.registry = setting(sess, "registry")
+		api.repository = setting(sess, "repository")
+		api.auth = setting(sess, "auth")
+		api.username = setting(sess, "username")
+		api.token = setting(sess, "token")
+		api.audience = setting(sess, "oidc.audience")
+		api.exchange = setting(sess, "oidc.exchange_url")
+		api.provider = setting(sess, "oidc.provider")
+		switch api.auth {
+		case AuthToken, AuthKeychain, AuthOIDC:
+		default:
+			return fmt.Errorf("%w: unknown auth %q", Error, api.auth)
+		}
+		if api.auth == AuthOIDC && api.exchange == "" {
+			return fmt.Errorf("%w: oidc auth requires oidc.exchange_url", Error)
+		}
+		return nil
+	})
+
+	return addon
+}
+
+// Login authenticates docker with the configured registry. With token
+// auth on ghcr.io GITHUB_TOKEN and GITHUB_ACTOR are used when token and
+// username are not configured, other registries require username.
+func (r *Registry) Login(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	username, password := r.username, r.token
+	switch r.auth {
+	case AuthKeychain:
+		return nil
+	case AuthOIDC:
+		user, token, err := OIDCCredentials(ctx, r.audience, r.exchange, r.provider)
+		if err != nil {
+			return err
+		}
+		if user != "" {
+			username = user
+		}
+		password = token
+	}
+	if r.registry == "ghcr.io" {
+		if password == "" {
+			password = os.Getenv("GITHUB_TOKEN")
+		}
+		if username == "" {
+			username = os.Getenv("GITHUB_ACTOR")
+		}
+	}
+	if password == "" {
+		return fmt.Errorf("%w: no token for %s", ErrAuth, r.registry)
+	}
+	if username == "" {
+		return fmt.Errorf("%w: no username for %s", ErrAuth, r.registry)
+	}
+	return r.docker.Login(ctx, r.registry, username, password)
+}
+
+// Publisher returns publisher pushing to the configured repository.
+func (r *Registry) Publisher() *Publisher {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	repo := r.repository
+	if !strings.HasPrefix(repo, r.registry+"/") {
+		repo = r.registry + "/" + repo
+	}
+	return NewPublisher(r.docker, repo)
+}
+
+func setting(sess *happy.Session, key string) string {
+	return sess.Settings().Get("registry." + key).Value().String()
+}
@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package registry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeDocker returns docker runner executing script logging its
+// arguments to returned log file.
+func fakeDocker(t *testing.T) (*Docker, string) {
+	t.Helper()
+	dir := t.TempDir()
+	log := filepath.Join(dir, "calls")
+	script := `#!/bin/sh
+echo "$@" >> ` + log + `
+case "$1 $2 $3" in
+push*) echo "$2: digest: sha256:$(printf %064d ${#2}) size: 528" ;;
+"buildx imagetools inspect") echo "sha256:$(printf %064d 1)" ;;
+esac
+`
+	bin := filepath.Join(dir, "docker")
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return &Docker{bin: bin}, log
+}
+
+func TestPushMultiPlatform(t *testing.T) {
+	docker, log := fakeDocker(t)
+	pushed, err := NewPublisher(docker, "ghcr.io/o/app").Push(context.Background(), PushRequest{
+		Tags: []string{"v1.2.0", "latest"},
+		Images: []Image{
+			{Ref: "app:amd64", Platform: "linux/amd64"},
+			{Ref: "app:arm64", Platform: "linux/arm64"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pushed) != 2 || pushed[1].Ref != "ghcr.io/o/app:latest" || !strings.HasPrefix(pushed[1].Digest, "sha256:") {
+		t.Errorf("unexpected pushed %+v", pushed)
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := string(data)
+	for _, want := range []string{
+		"tag app:arm64 ghcr.io/o/app:v1.2.0-linux-arm64",
+		"push ghcr.io/o/app:v1.2.0-linux-amd64",
+		"buildx imagetools create --tag ghcr.io/o/app:latest ghcr.io/o/app@sha256:",
+	} {
+		if !strings.Contains(calls, want) {
+			t.Errorf("missing call %q in\n%s", want, calls)
+		}
+	}
+}
+
+func TestPushSinglePlatform(t *testing.T) {
+	docker, _ := fakeDocker(t)
+	pushed, err := NewPublisher(docker, "docker.io/u/app").Push(context.Background(), PushRequest{
+		Tags:   []string{"v1.0.0"},
+		Images: []Image{{Ref: "app:dev"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pushed) != 1 || pushed[0].Ref != "docker.io/u/app:v1.0.0" || len(pushed[0].Digest) != len("sha256:")+64 {
+		t.Errorf("unexpected pushed %+v", pushed)
+	}
+}
+
+func TestActionsIDToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer req" || r.URL.Query().Get("audience") != "ghcr.io" {
+			t.Errorf("unexpected request %s %s", r.URL, r.Header.Get("Authorization"))
+		}
+		_, _ = w.Write([]byte(`{"value":"oidc"}`))
+	}))
+	defer srv.Close()
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", srv.URL+"/token?api-version=2.0")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "req")
+
+	token, err := ActionsIDToken(context.Background(), "ghcr.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "oidc" {
+		t.Errorf("unexpected token %q", token)
+	}
+}
+
+func TestOIDCCredentials(t *testing.T) {
+	actions := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"value":"id-token"}`))
+	}))
+	defer actions.Close()
+	exchange := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Method != http.MethodPost {
+			t.Errorf("unexpected exchange request %s: %v", r.Method, err)
+		}
+		if r.PostForm.Get("grant_type") != tokenExchangeGrant ||
+			r.PostForm.Get("subject_token") != "id-token" ||
+			r.PostForm.Get("provider_name") != "github" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"registry-token","username":"ci-bot","token_type":"Bearer"}`))
+	}))
+	defer exchange.Close()
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", actions.URL+"/token?api-version=2.0")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "req")
+
+	username, token, err := OIDCCredentials(context.Background(), "registry.example.com", exchange.URL, "github")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if username != "ci-bot" || token != "registry-token" {
+		t.Errorf("unexpected credentials %q %q", username, token)
+	}
+
+	if _, _, err := OIDCCredentials(context.Background(), "", exchange.URL, "other"); !errors.Is(err, ErrAuth) {
+		t.Errorf("expected rejected exchange to fail with ErrAuth, got %v", err)
+	}
+	if _, _, err := OIDCCredentials(context.Background(), "", "", ""); !errors.Is(err, ErrAuth) {
+		t.Errorf("expected missing exchange url to fail with ErrAuth, got %v", err)
+	}
+}
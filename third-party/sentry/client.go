@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultBaseURL = "https://sentry.io/"
+
+var (
+	Error   = errors.New("sentry")
+	ErrAPI  = fmt.Errorf("%w: api error", Error)
+	ErrConf = fmt.Errorf("%w: invalid configuration", Error)
+)
+
+// Commit is a commit associated with release.
+type Commit struct {
+	ID          string    `json:"id"`
+	Message     string    `json:"message,omitempty"`
+	AuthorName  string    `json:"author_name,omitempty"`
+	AuthorEmail string    `json:"author_email,omitempty"`
+	Timestamp   time.Time `json:"timestamp,omitempty"`
+	Repository  string    `json:"repository,omitempty"`
+}
+
+// ReleaseParams are parameters to create a release.
+type ReleaseParams struct {
+	Version  string   `json:"version"`
+	Projects []string `json:"projects"`
+	URL      string   `json:"url,omitempty"`
+	Commits  []Commit `json:"commits,omitempty"`
+}
+
+// Release is a Sentry release.
+type Release struct {
+	Version      string     `json:"version"`
+	DateCreated  time.Time  `json:"dateCreated"`
+	DateReleased *time.Time `json:"dateReleased"`
+	CommitCount  int        `json:"commitCount"`
+}
+
+// Deploy is a deploy of release to environment.
+type Deploy struct {
+	Environment  string     `json:"environment"`
+	Name         string     `json:"name,omitempty"`
+	URL          string     `json:"url,omitempty"`
+	DateStarted  *time.Time `json:"dateStarted,omitempty"`
+	DateFinished *time.Time `json:"dateFinished,omitempty"`
+}
+
+// Client is Sentry web API client authenticated with auth token.
+type Client struct {
+	baseURL *url.URL
+	token   string
+	http    *http.Client
+}
+
+// NewClient returns client of Sentry at baseURL, sentry.io when empty.
+func NewClient(baseURL, token string) (*Client, error) {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: base url: %s", ErrConf, err)
+	}
+	return &Client{
+		baseURL: u,
+		token:   token,
+		http:    &http.Client{Timeout: time.Minute},
+	}, nil
+}
+
+// CreateRelease creates release of org, or updates it when version exists.
+func (c *Client) CreateRelease(ctx context.Context, org string, params ReleaseParams) (*Release, error) {
+	rel := &Release{}
+	return rel, c.do(ctx, http.MethodPost, orgPath(org, "releases"), params, rel)
+}
+
+// FinalizeRelease marks release version as released at t.
+func (c *Client) FinalizeRelease(ctx context.Context, org, version string, t time.Time) (*Release, error) {
+	rel := &Release{}
+	body := map[string]time.Time{"dateReleased": t}
+	return rel, c.do(ctx, http.MethodPut, orgPath(org, "releases", url.PathEscape(version)), body, rel)
+}
+
+// CreateDeploy records deploy of release version.
+func (c *Client) CreateDeploy(ctx context.Context, org, version string, deploy Deploy) error {
+	return c.do(ctx, http.MethodPost, orgPath(org, "releases", url.PathEscape(version), "deploys"), deploy, nil)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	u, err := c.baseURL.Parse(path)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrAPI, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrAPI, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w: %s %s: %d %s", ErrAPI, method, u.Path, resp.StatusCode, bytes.TrimSpace(body))
+	}
+	if out != nil && len(body) > 0 {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("%w: %s", ErrAPI, err)
+		}
+	}
+	return nil
+}
+
+func orgPath(org string, elem ...string) string {
+	return "api/0/organizations/" + url.PathEscape(org) + "/" + strings.Join(elem, "/") + "/"
+}
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package sentry
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/happy-sdk/happy"
+	"github.com/happy-sdk/happy/sdk/settings"
+)
+
+type Settings struct {
+	URL         settings.String `key:"url" default:"https://sentry.io/" mutation:"once"`
+	Org         settings.String `key:"org" mutation:"once"`
+	Project     settings.String `key:"project" mutation:"once"`
+	Token       settings.String `key:"token" mutation:"once"`
+	Environment settings.String `key:"environment" default:"production" mutation:"once"`
+}
+
+func (s Settings) Blueprint() (*settings.Blueprint, error) {
+	b, err := settings.New(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Sentry is the API provided by the sentry addon.
+type Sentry struct {
+	happy.API
+
+	mu      sync.Mutex
+	tracker *Tracker
+}
+
+func Addon(s Settings) *happy.Addon {
+	addon := happy.NewAddon("sentry", s)
+
+	api := &Sentry{}
+	addon.ProvidesAPI(api)
+
+	addon.OnRegister(func(sess *happy.Session) error {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		token := setting(sess, "token")
+		if token == "" {
+			token = os.Getenv("SENTRY_AUTH_TOKEN")
+		}
+		client, err := NewClient(setting(sess, "url"), token)
+		if err != nil {
+			return err
+		}
+		var projects []string
+		for _, p := range strings.Split(setting(sess, "project"), ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				projects = append(projects, p)
+			}
+		}
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		api.tracker = NewTracker(client, setting(sess, "org"), projects, setting(sess, "environment"), wd)
+		return nil
+	})
+
+	return addon
+}
+
+// Tracker returns tracker creating releases in the configured org
+// and projects.
+func (s *Sentry) Tracker() *Tracker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tracker
+}
+
+func setting(sess *happy.Session, key string) string {
+	return sess.Settings().Get("sentry." + key).Value().String()
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package sentry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+)
+
+func gitRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=Happy", "-c", "user.email=happy@example.com", "commit", "-q", "--allow-empty", "-m", "first"},
+		{"tag", "v1.0.0"},
+		{"-c", "user.name=Happy", "-c", "user.email=happy@example.com", "commit", "-q", "--allow-empty", "-m", "second"},
+		{"tag", "v1.1.0"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Skipf("git %v: %s: %s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestTrack(t *testing.T) {
+	dir := gitRepo(t)
+	var (
+		created ReleaseParams
+		deploy  Deploy
+		calls   []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+		calls = append(calls, r.Method+" "+r.URL.EscapedPath())
+		switch r.URL.EscapedPath() {
+		case "/api/0/organizations/org/releases/":
+			_ = json.NewDecoder(r.Body).Decode(&created)
+		case "/api/0/organizations/org/releases/app@1.1.0/deploys/":
+			_ = json.NewDecoder(r.Body).Decode(&deploy)
+		}
+		_, _ = w.Write([]byte(`{"version":"app@1.1.0"}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "tok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = NewTracker(client, "org", []string{"app"}, "production", dir).Track(context.Background(), Published{
+		Version:     "app@1.1.0",
+		Tag:         "v1.1.0",
+		PreviousTag: "v1.0.0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created.Commits) != 1 || created.Commits[0].Message != "second" || created.Commits[0].AuthorEmail != "happy@example.com" {
+		t.Errorf("unexpected commits %+v", created.Commits)
+	}
+	if len(calls) != 3 || calls[1] != "PUT /api/0/organizations/org/releases/app@1.1.0/" {
+		t.Errorf("unexpected calls %v", calls)
+	}
+	if deploy.Environment != "production" || deploy.DateFinished == nil {
+		t.Errorf("unexpected deploy %+v", deploy)
+	}
+}
+
+func TestTrackAPIError(t *testing.T) {
+	dir := gitRepo(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"detail":"You do not have permission"}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "tok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = NewTracker(client, "org", []string{"app"}, "", dir).Track(context.Background(), Published{Version: "1", Tag: "v1.1.0"})
+	if !errors.Is(err, ErrAPI) {
+		t.Errorf("expected ErrAPI, got %v", err)
+	}
+	if err := NewTracker(client, "", nil, "", dir).Track(context.Background(), Published{}); !errors.Is(err, ErrConf) {
+		t.Errorf("expected ErrConf, got %v", err)
+	}
+}
+
+func TestCommitRangeFirstRelease(t *testing.T) {
+	dir := gitRepo(t)
+	for i := 0; i < initialCommits; i++ {
+		if out, err := exec.Command("git", "-C", dir, "-c", "user.name=Happy", "-c", "user.email=happy@example.com",
+			"commit", "-q", "--allow-empty", "-m", "change").CombinedOutput(); err != nil {
+			t.Fatalf("git commit: %s: %s", err, out)
+		}
+	}
+	commits, err := CommitRange(context.Background(), dir, "", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != initialCommits || commits[0].Message != "change" {
+		t.Errorf("expected latest %d commits, got %d", initialCommits, len(commits))
+	}
+}
@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package sentry
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Published describes a published version tracked as Sentry release.
+type Published struct {
+	// Version is Sentry release version e.g. "app@1.2.0".
+	Version string
+	// Tag is the release tag, end of commit range.
+	Tag string
+	// PreviousTag is previous release tag, start of commit range.
+	// When empty, on first release, only the latest initialCommits
+	// commits up to tag are associated, as Sentry does for releases
+	// without previous one.
+	PreviousTag string
+	// URL of the published release.
+	URL string
+}
+
+// Tracker creates Sentry releases for published versions.
+type Tracker struct {
+	client      *Client
+	org         string
+	projects    []string
+	environment string
+	// dir is git repository commits are read from.
+	dir string
+}
+
+// NewTracker returns tracker creating releases of projects in org from
+// commits of git repository at dir. Deploys are marked in environment
+// when it is not empty.
+func NewTracker(client *Client, org string, projects []string, environment, dir string) *Tracker {
+	return &Tracker{
+		client:      client,
+		org:         org,
+		projects:    projects,
+		environment: environment,
+		dir:         dir,
+	}
+}
+
+// Track creates release p.Version with commits of changelog range,
+// finalizes it and marks deploy.
+func (t *Tracker) Track(ctx context.Context, p Published) error {
+	if t.org == "" || len(t.projects) == 0 {
+		return fmt.Errorf("%w: org and project are required", ErrConf)
+	}
+	commits, err := CommitRange(ctx, t.dir, p.PreviousTag, p.Tag)
+	if err != nil {
+		return err
+	}
+	if _, err := t.client.CreateRelease(ctx, t.org, ReleaseParams{
+		Version:  p.Version,
+		Projects: t.projects,
+		URL:      p.URL,
+		Commits:  commits,
+	}); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if _, err := t.client.FinalizeRelease(ctx, t.org, p.Version, now); err != nil {
+		return err
+	}
+	if t.environment == "" {
+		return nil
+	}
+	return t.client.CreateDeploy(ctx, t.org, p.Version, Deploy{
+		Environment:  t.environment,
+		URL:          p.URL,
+		DateFinished: &now,
+	})
+}
+
+// initialCommits is number of commits associated with the first release,
+// whole history would exceed request size accepted by Sentry.
+const initialCommits = 20
+
+// CommitRange returns commits of git repository at dir reachable from
+// to and not from from. When from is empty only the latest initialCommits
+// commits reachable from to are returned.
+func CommitRange(ctx context.Context, dir, from, to string) ([]Commit, error) {
+	args := []string{"-C", dir, "log", "--format=%H%x1f%s%x1f%an%x1f%ae%x1f%aI"}
+	rng := to
+	if from != "" {
+		rng = from + ".." + to
+	} else {
+		args = append(args, "-n", strconv.Itoa(initialCommits))
+	}
+	out, err := exec.CommandContext(ctx, "git", append(args, rng)...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: git log %s: %s", Error, rng, err)
+	}
+	return parseCommits(out)
+}
+
+func parseCommits(out []byte) ([]Commit, error) {
+	var commits []Commit
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		f := strings.Split(scanner.Text(), "\x1f")
+		if len(f) != 5 {
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339, f[4])
+		if err != nil {
+			return nil, fmt.Errorf("%w: commit %s: %s", Error, f[0], err)
+		}
+		commits = append(commits, Commit{
+			ID:          f[0],
+			Message:     f[1],
+			AuthorName:  f[2],
+			AuthorEmail: f[3],
+			Timestamp:   ts,
+		})
+	}
+	return commits, scanner.Err()
+}
@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultAPIURL      = "https://slack.com/api/"
+	maxRateLimitWait   = time.Minute
+	defaultHTTPTimeout = 30 * time.Second
+)
+
+var (
+	Error         = errors.New("slack")
+	ErrNoChannel  = fmt.Errorf("%w: no webhook or channel configured", Error)
+	ErrPostFailed = fmt.Errorf("%w: post message failed", Error)
+)
+
+// Text is a Slack text object.
+type Text struct {
+	// Type is mrkdwn or plain_text.
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Block is a Slack layout block. Only section and divider blocks used
+// by release messages are modeled.
+type Block struct {
+	Type string `json:"type"`
+	Text *Text  `json:"text,omitempty"`
+}
+
+// Message is a Slack message.
+type Message struct {
+	// Channel is ignored by incoming webhooks which post to the
+	// channel they were created for.
+	Channel string `json:"channel,omitempty"`
+	// Text is the notification fallback of blocks.
+	Text   string  `json:"text"`
+	Blocks []Block `json:"blocks,omitempty"`
+}
+
+// ClientOption configures a Client.
+type ClientOption func(c *Client)
+
+// WithWebhook posts messages to incoming webhook url.
+func WithWebhook(url string) ClientOption {
+	return func(c *Client) {
+		c.webhook = url
+	}
+}
+
+// WithBotToken posts messages with chat.postMessage authenticated
+// with bot token.
+func WithBotToken(token string) ClientOption {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithAPIURL sets Web API base url.
+func WithAPIURL(u string) ClientOption {
+	return func(c *Client) {
+		c.apiURL = u
+	}
+}
+
+// WithHTTPClient sets the underlying http client.
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *Client) {
+		c.http = hc
+	}
+}
+
+// Client posts messages to Slack using incoming webhook or bot token.
+type Client struct {
+	webhook string
+	token   string
+	apiURL  string
+	http    *http.Client
+}
+
+// NewClient returns new Slack client.
+func NewClient(opts ...ClientOption) *Client {
+	c := &Client{
+		apiURL: defaultAPIURL,
+		http:   &http.Client{Timeout: defaultHTTPTimeout},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Post posts msg to channels. With bot token message is posted to every
+// channel, otherwise to the incoming webhook and channels are ignored.
+func (c *Client) Post(ctx context.Context, msg Message, channels ...string) error {
+	if c.token != "" && len(channels) > 0 {
+		var errs []error
+		for _, ch := range channels {
+			m := msg
+			m.Channel = ch
+			if err := c.postMessage(ctx, m); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", ch, err))
+			}
+		}
+		return errors.Join(errs...)
+	}
+	if c.webhook == "" {
+		return ErrNoChannel
+	}
+	_, err := c.post(ctx, c.webhook, msg)
+	return err
+}
+
+func (c *Client) postMessage(ctx context.Context, msg Message) error {
+	body, err := c.post(ctx, c.apiURL+"chat.postMessage", msg)
+	if err != nil {
+		return err
+	}
+	var out struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return fmt.Errorf("%w: %s", ErrPostFailed, err)
+	}
+	if !out.OK {
+		return fmt.Errorf("%w: %s", ErrPostFailed, out.Error)
+	}
+	return nil
+}
+
+// post sends JSON payload to url, waiting out rate limit once.
+func (c *Client) post(ctx context.Context, url string, payload any) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	for retried := false; ; retried = true {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		if c.token != "" && url != c.webhook {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrPostFailed, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrPostFailed, err)
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && !retried {
+			wait, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+			if d := time.Duration(wait) * time.Second; d <= maxRateLimitWait {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(d):
+				}
+				continue
+			}
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return nil, fmt.Errorf("%w: %d %s", ErrPostFailed, resp.StatusCode, bytes.TrimSpace(body))
+		}
+		return body, nil
+	}
+}
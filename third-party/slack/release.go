@@ -0,0 +1,166 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package slack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxChangelogLines limits changelog summary posted on success.
+	maxChangelogLines = 10
+	// maxSectionText is the longest text Slack accepts in section block.
+	maxSectionText = 3000
+)
+
+// Release describes a release reported to Slack.
+type Release struct {
+	// Project is name of the released project or module.
+	Project string
+	// Tags are pushed release tags.
+	Tags []string
+	// URL of the published release.
+	URL string
+	// Changelog of the release, summarized in success message.
+	Changelog string
+	// Task is name of the failing release task.
+	Task string
+	// Err is error release failed with.
+	Err error
+}
+
+// Notifier posts release lifecycle messages to configured channels.
+type Notifier struct {
+	client   *Client
+	channels []string
+}
+
+// NewNotifier returns notifier posting with client to channels.
+func NewNotifier(client *Client, channels ...string) *Notifier {
+	return &Notifier{client: client, channels: channels}
+}
+
+// Started posts that release of r has started.
+func (n *Notifier) Started(ctx context.Context, r Release) error {
+	return n.client.Post(ctx, StartedMessage(r), n.channels...)
+}
+
+// Succeeded posts that r was released with changelog summary.
+func (n *Notifier) Succeeded(ctx context.Context, r Release) error {
+	return n.client.Post(ctx, SucceededMessage(r), n.channels...)
+}
+
+// Failed posts that release of r failed in r.Task.
+func (n *Notifier) Failed(ctx context.Context, r Release) error {
+	return n.client.Post(ctx, FailedMessage(r), n.channels...)
+}
+
+// StartedMessage formats release started message.
+func StartedMessage(r Release) Message {
+	text := fmt.Sprintf(":rocket: Release of *%s* started", escape(r.Project))
+	if len(r.Tags) > 0 {
+		text += ": " + tagList(r.Tags)
+	}
+	return Message{
+		Text:   text,
+		Blocks: []Block{section(text)},
+	}
+}
+
+// SucceededMessage formats release succeeded message with changelog summary.
+func SucceededMessage(r Release) Message {
+	text := fmt.Sprintf(":white_check_mark: Released *%s* %s", escape(r.Project), tagList(r.Tags))
+	if r.URL != "" {
+		text += fmt.Sprintf(" (<%s|release notes>)", r.URL)
+	}
+	msg := Message{
+		Text:   text,
+		Blocks: []Block{section(text)},
+	}
+	if summary := changelogSummary(r.Changelog); summary != "" {
+		msg.Blocks = append(msg.Blocks, Block{Type: "divider"}, section(summary))
+	}
+	return msg
+}
+
+// FailedMessage formats release failed message with the failing task.
+func FailedMessage(r Release) Message {
+	text := fmt.Sprintf(":x: Release of *%s* failed", escape(r.Project))
+	if r.Task != "" {
+		text += fmt.Sprintf(" in task `%s`", escape(r.Task))
+	}
+	msg := Message{
+		Text:   text,
+		Blocks: []Block{section(text)},
+	}
+	if r.Err != nil {
+		msg.Blocks = append(msg.Blocks, section("```"+truncate(escape(r.Err.Error()), maxSectionText-6)+"```"))
+	}
+	return msg
+}
+
+func section(text string) Block {
+	return Block{Type: "section", Text: &Text{Type: "mrkdwn", Text: text}}
+}
+
+func tagList(tags []string) string {
+	quoted := make([]string, len(tags))
+	for i, t := range tags {
+		quoted[i] = "`" + escape(t) + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// changelogSummary returns first entries of markdown changelog,
+// noting how many entries were left out, truncated to section limit.
+func changelogSummary(changelog string) string {
+	var lines []string
+	more := 0
+	for _, line := range strings.Split(changelog, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if len(lines) >= maxChangelogLines {
+			more++
+			continue
+		}
+		if h := strings.TrimLeft(line, "#"); h != line {
+			// slack has no headings
+			line = "*" + strings.TrimSpace(h) + "*"
+		}
+		lines = append(lines, escape(line))
+	}
+	if more > 0 {
+		lines = append(lines, fmt.Sprintf("_and %d more_", more))
+	}
+	return truncate(strings.Join(lines, "\n"), maxSectionText)
+}
+
+// truncate shortens escaped mrkdwn s to at most max bytes ending with
+// ellipsis, without splitting UTF-8 characters or escaped entities.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	const ellipsis = "…"
+	cut := max - len(ellipsis)
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	s = s[:cut]
+	if i := strings.LastIndexByte(s, '&'); i >= 0 && !strings.Contains(s[i:], ";") {
+		s = s[:i]
+	}
+	return s + ellipsis
+}
+
+// escape escapes control characters of Slack mrkdwn.
+func escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package slack
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/happy-sdk/happy"
+	"github.com/happy-sdk/happy/sdk/settings"
+)
+
+type Settings struct {
+	WebhookURL settings.String `key:"webhook_url" mutation:"once"`
+	Token      settings.String `key:"token" mutation:"once"`
+	Channels   settings.String `key:"channels" mutation:"once"`
+}
+
+func (s Settings) Blueprint() (*settings.Blueprint, error) {
+	b, err := settings.New(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Slack is the API provided by the slack addon.
+type Slack struct {
+	happy.API
+
+	mu       sync.Mutex
+	webhook  string
+	token    string
+	channels []string
+}
+
+func Addon(s Settings) *happy.Addon {
+	addon := happy.NewAddon("slack", s)
+
+	api := &Slack{}
+	addon.ProvidesAPI(api)
+
+	addon.OnRegister(func(sess *happy.Session) error {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		api.webhook = setting(sess, "webhook_url")
+		api.token = setting(sess, "token")
+		for _, ch := range strings.Split(setting(sess, "channels"), ",") {
+			if ch = strings.TrimSpace(ch); ch != "" {
+				api.channels = append(api.channels, ch)
+			}
+		}
+		return nil
+	})
+
+	return addon
+}
+
+// Notifier returns notifier posting release messages to the configured
+// channels with bot token, or to the incoming webhook.
+func (sl *Slack) Notifier() *Notifier {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	var opts []ClientOption
+	if sl.webhook != "" {
+		opts = append(opts, WithWebhook(sl.webhook))
+	}
+	if sl.token != "" {
+		opts = append(opts, WithBotToken(sl.token))
+	}
+	return NewNotifier(NewClient(opts...), sl.channels...)
+}
+
+func setting(sess *happy.Session, key string) string {
+	return sess.Settings().Get("slack." + key).Value().String()
+}
@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPostWebhook(t *testing.T) {
+	var got Message
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Error("webhook request must not be authenticated")
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	n := NewNotifier(NewClient(WithWebhook(srv.URL)), "#ignored")
+	if err := n.Started(context.Background(), Release{Project: "happy", Tags: []string{"v1.0.0"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Channel != "" || !strings.Contains(got.Text, "`v1.0.0`") {
+		t.Errorf("unexpected message %+v", got)
+	}
+}
+
+func TestPostBotToken(t *testing.T) {
+	var channels []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat.postMessage" || r.Header.Get("Authorization") != "Bearer xoxb" {
+			t.Errorf("unexpected request %s %s", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var m Message
+		_ = json.NewDecoder(r.Body).Decode(&m)
+		channels = append(channels, m.Channel)
+		if m.Channel == "#private" {
+			_, _ = w.Write([]byte(`{"ok":false,"error":"not_in_channel"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBotToken("xoxb"), WithAPIURL(srv.URL+"/"))
+	err := NewNotifier(c, "#releases", "#private").Failed(context.Background(), Release{
+		Project: "happy",
+		Task:    "publish",
+		Err:     errors.New("upload <failed>"),
+	})
+	if !errors.Is(err, ErrPostFailed) || !strings.Contains(err.Error(), "not_in_channel") {
+		t.Errorf("expected not_in_channel error, got %v", err)
+	}
+	if len(channels) != 2 {
+		t.Errorf("expected post to every channel, got %v", channels)
+	}
+}
+
+func TestSucceededMessage(t *testing.T) {
+	var changelog strings.Builder
+	changelog.WriteString("## Features\n")
+	for i := 0; i < 12; i++ {
+		fmt.Fprintf(&changelog, "- change %d <x>\n", i)
+	}
+	msg := SucceededMessage(Release{
+		Project:   "happy",
+		Tags:      []string{"v1.1.0"},
+		URL:       "https://example.com/r",
+		Changelog: changelog.String(),
+	})
+	if !strings.Contains(msg.Text, "<https://example.com/r|release notes>") {
+		t.Errorf("unexpected text %q", msg.Text)
+	}
+	if len(msg.Blocks) != 3 {
+		t.Fatalf("expected 3 blocks, got %d", len(msg.Blocks))
+	}
+	summary := msg.Blocks[2].Text.Text
+	if !strings.HasPrefix(summary, "*Features*") ||
+		!strings.Contains(summary, "&lt;x&gt;") ||
+		!strings.HasSuffix(summary, "_and 3 more_") {
+		t.Errorf("unexpected summary %q", summary)
+	}
+}
+
+func TestFailedMessageLongError(t *testing.T) {
+	msg := FailedMessage(Release{
+		Project: "happy",
+		Task:    "test",
+		Err:     errors.New(strings.Repeat("go test: FAIL <x>\n", 500)),
+	})
+	if len(msg.Blocks) != 2 {
+		t.Fatalf("expected 2 blocks, got %d", len(msg.Blocks))
+	}
+	text := msg.Blocks[1].Text.Text
+	if len(text) > maxSectionText || !strings.HasSuffix(text, "…```") || !strings.HasPrefix(text, "```go test: FAIL &lt;x&gt;") {
+		t.Errorf("unexpected error section of %d bytes: %q...", len(text), text[len(text)-20:])
+	}
+}
+
+func TestSucceededMessageLongChangelog(t *testing.T) {
+	var changelog strings.Builder
+	for i := 0; i < 5; i++ {
+		fmt.Fprintf(&changelog, "- %s\n", strings.Repeat("fix <x> ", 100))
+	}
+	msg := SucceededMessage(Release{Project: "happy", Tags: []string{"v1.1.0"}, Changelog: changelog.String()})
+	if len(msg.Blocks) != 3 {
+		t.Fatalf("expected 3 blocks, got %d", len(msg.Blocks))
+	}
+	summary := msg.Blocks[2].Text.Text
+	if len(summary) > maxSectionText || !strings.HasSuffix(summary, "…") || !strings.HasPrefix(summary, "- fix &lt;x&gt;") {
+		t.Errorf("unexpected summary of %d bytes", len(summary))
+	}
+}
+
+func TestPostNoChannel(t *testing.T) {
+	if err := NewClient().Post(context.Background(), Message{Text: "x"}); !errors.Is(err, ErrNoChannel) {
+		t.Errorf("expected ErrNoChannel, got %v", err)
+	}
+}
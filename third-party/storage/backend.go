@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+var (
+	Error          = errors.New("storage")
+	ErrUpload      = fmt.Errorf("%w: upload failed", Error)
+	ErrUnsupported = fmt.Errorf("%w: unsupported provider", Error)
+)
+
+// Providers.
+const (
+	ProviderS3    = "s3"
+	ProviderGCS   = "gcs"
+	ProviderAzure = "azure"
+)
+
+// Backend uploads files to a bucket.
+type Backend interface {
+	// Upload stores file at path under key. Public objects are readable
+	// anonymously where provider supports per-object ACLs.
+	Upload(ctx context.Context, path, key string, public bool) error
+	// URL returns url of object key.
+	URL(key string) string
+}
+
+// BackendConfig configures provider backend.
+type BackendConfig struct {
+	// Provider is one of ProviderS3, ProviderGCS or ProviderAzure.
+	Provider string
+	// Bucket is bucket name, Azure container name.
+	Bucket string
+	// Endpoint is custom S3 compatible endpoint url.
+	Endpoint string
+	// Account is Azure storage account name.
+	Account string
+}
+
+// NewBackend returns backend uploading with provider CLI: aws, gcloud
+// or az. CLIs authenticate with their own credential chains, which
+// covers environment credentials, profiles and CI workload identity.
+func NewBackend(cfg BackendConfig) (Backend, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("%w: bucket is required", Error)
+	}
+	switch cfg.Provider {
+	case ProviderS3:
+		return &s3Backend{cfg}, nil
+	case ProviderGCS:
+		return &gcsBackend{cfg}, nil
+	case ProviderAzure:
+		if cfg.Account == "" {
+			return nil, fmt.Errorf("%w: azure storage account is required", Error)
+		}
+		return &azureBackend{cfg}, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupported, cfg.Provider)
+}
+
+type s3Backend struct {
+	cfg BackendConfig
+}
+
+func (b *s3Backend) Upload(ctx context.Context, path, key string, public bool) error {
+	args := []string{"s3", "cp", path, "s3://" + b.cfg.Bucket + "/" + key, "--only-show-errors"}
+	if b.cfg.Endpoint != "" {
+		args = append(args, "--endpoint-url", b.cfg.Endpoint)
+	}
+	if public {
+		args = append(args, "--acl", "public-read")
+	}
+	return run(ctx, "aws", args...)
+}
+
+func (b *s3Backend) URL(key string) string {
+	if b.cfg.Endpoint != "" {
+		return strings.TrimSuffix(b.cfg.Endpoint, "/") + "/" + b.cfg.Bucket + "/" + key
+	}
+	return "https://" + b.cfg.Bucket + ".s3.amazonaws.com/" + key
+}
+
+type gcsBackend struct {
+	cfg BackendConfig
+}
+
+func (b *gcsBackend) Upload(ctx context.Context, path, key string, public bool) error {
+	args := []string{"storage", "cp", path, "gs://" + b.cfg.Bucket + "/" + key}
+	if public {
+		args = append(args, "--predefined-acl=publicRead")
+	}
+	return run(ctx, "gcloud", args...)
+}
+
+func (b *gcsBackend) URL(key string) string {
+	return "https://storage.googleapis.com/" + b.cfg.Bucket + "/" + key
+}
+
+// azureBackend uploads blobs to container. Azure has no per-blob ACL,
+// anonymous access is configured on the container.
+type azureBackend struct {
+	cfg BackendConfig
+}
+
+func (b *azureBackend) Upload(ctx context.Context, path, key string, public bool) error {
+	if public {
+		return errAzurePublic(b.cfg.Bucket)
+	}
+	return run(ctx, "az", "storage", "blob", "upload",
+		"--account-name", b.cfg.Account,
+		"--container-name", b.cfg.Bucket,
+		"--name", key,
+		"--file", path,
+		"--overwrite",
+		"--auth-mode", "login",
+		"--only-show-errors",
+	)
+}
+
+// errAzurePublic returns error of public upload to azure container.
+func errAzurePublic(container string) error {
+	return fmt.Errorf("%w: azure blobs can not be made public, allow anonymous read access on container %s instead", Error, container)
+}
+
+func (b *azureBackend) URL(key string) string {
+	return "https://" + b.cfg.Account + ".blob.core.windows.net/" + b.cfg.Bucket + "/" + key
+}
+
+func run(ctx context.Context, name string, args ...string) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: %s %s: %s: %s", ErrUpload, name, strings.Join(args[:2], " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// DefaultLayout is default object key layout.
+const DefaultLayout = "{{ .Project }}/{{ .Version }}/{{ .File }}"
+
+// ChecksumsFile is name of uploaded checksums file.
+const ChecksumsFile = "checksums.txt"
+
+// PublishRequest describes artifacts of a single release to upload.
+type PublishRequest struct {
+	Project string
+	Version string
+	Tag     string
+	// Assets are paths of dist artifacts.
+	Assets []string
+	// Manifest is path of the release manifest, uploaded when set.
+	Manifest string
+}
+
+// Object is an uploaded object.
+type Object struct {
+	Key    string
+	URL    string
+	SHA256 string
+}
+
+// PublisherOption configures a Publisher.
+type PublisherOption func(p *Publisher) error
+
+// WithLayout sets object key layout, a text/template rendered with
+// Project, Version, Tag and File.
+func WithLayout(layout string) PublisherOption {
+	return func(p *Publisher) error {
+		t, err := template.New("layout").Parse(layout)
+		if err != nil {
+			return fmt.Errorf("%w: invalid layout: %s", Error, err)
+		}
+		p.layout = t
+		return nil
+	}
+}
+
+// WithPublic makes uploaded objects publicly readable.
+func WithPublic(public bool) PublisherOption {
+	return func(p *Publisher) error {
+		p.public = public
+		return nil
+	}
+}
+
+// Publisher uploads release artifacts, their checksums and release
+// manifest to object storage.
+type Publisher struct {
+	backend Backend
+	layout  *template.Template
+	public  bool
+}
+
+// NewPublisher returns publisher uploading to backend. Public uploads
+// are rejected for azure backend.
+func NewPublisher(backend Backend, opts ...PublisherOption) (*Publisher, error) {
+	p := &Publisher{backend: backend}
+	if err := WithLayout(DefaultLayout)(p); err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			return nil, err
+		}
+	}
+	if b, ok := backend.(*azureBackend); ok && p.public {
+		// fail before anything is uploaded, see azureBackend.Upload
+		return nil, errAzurePublic(b.cfg.Bucket)
+	}
+	return p, nil
+}
+
+// Publish uploads assets of every request followed by checksums file
+// and release manifest.
+func (p *Publisher) Publish(ctx context.Context, reqs ...PublishRequest) ([]Object, error) {
+	var objects []Object
+	for _, r := range reqs {
+		objs, err := p.publish(ctx, r)
+		objects = append(objects, objs...)
+		if err != nil {
+			return objects, fmt.Errorf("%w: publish %s %s: %w", Error, r.Project, r.Version, err)
+		}
+	}
+	return objects, nil
+}
+
+func (p *Publisher) publish(ctx context.Context, r PublishRequest) ([]Object, error) {
+	if err := checkNames(r); err != nil {
+		return nil, err
+	}
+	var objects []Object
+	sums := make(map[string]string, len(r.Assets))
+	for _, asset := range r.Assets {
+		obj, err := p.upload(ctx, r, asset)
+		if err != nil {
+			return objects, err
+		}
+		sums[filepath.Base(asset)] = obj.SHA256
+		objects = append(objects, obj)
+	}
+
+	if len(sums) > 0 {
+		dir, err := os.MkdirTemp("", "happy-storage-")
+		if err != nil {
+			return objects, err
+		}
+		defer os.RemoveAll(dir)
+		checksums := filepath.Join(dir, ChecksumsFile)
+		if err := os.WriteFile(checksums, formatChecksums(sums), 0o644); err != nil {
+			return objects, err
+		}
+		obj, err := p.upload(ctx, r, checksums)
+		if err != nil {
+			return objects, err
+		}
+		objects = append(objects, obj)
+	}
+
+	if r.Manifest != "" {
+		obj, err := p.upload(ctx, r, r.Manifest)
+		if err != nil {
+			return objects, err
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+func (p *Publisher) upload(ctx context.Context, r PublishRequest, file string) (Object, error) {
+	sum, err := sha256File(file)
+	if err != nil {
+		return Object{}, err
+	}
+	key, err := p.Key(r, filepath.Base(file))
+	if err != nil {
+		return Object{}, err
+	}
+	if err := p.backend.Upload(ctx, file, key, p.public); err != nil {
+		return Object{}, err
+	}
+	return Object{Key: key, URL: p.backend.URL(key), SHA256: sum}, nil
+}
+
+// checkNames returns error when uploaded files of r share file name,
+// which would overwrite each other's object.
+func checkNames(r PublishRequest) error {
+	files := append([]string{ChecksumsFile}, r.Assets...)
+	if r.Manifest != "" {
+		files = append(files, r.Manifest)
+	}
+	seen := make(map[string]bool, len(files))
+	for _, file := range files {
+		name := filepath.Base(file)
+		if seen[name] {
+			return fmt.Errorf("%w: %s conflicts with another uploaded file of the same name", Error, file)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// Key returns object key of file of release r.
+func (p *Publisher) Key(r PublishRequest, file string) (string, error) {
+	var b strings.Builder
+	err := p.layout.Execute(&b, struct {
+		Project string
+		Version string
+		Tag     string
+		File    string
+	}{r.Project, r.Version, r.Tag, file})
+	if err != nil {
+		return "", fmt.Errorf("%w: layout: %s", Error, err)
+	}
+	key := path.Clean(strings.TrimPrefix(b.String(), "/"))
+	if key == "." || strings.HasPrefix(key, "../") {
+		return "", fmt.Errorf("%w: invalid object key %q", Error, b.String())
+	}
+	return key, nil
+}
+
+// formatChecksums formats checksums in sha256sum format.
+func formatChecksums(sums map[string]string) []byte {
+	names := make([]string, 0, len(sums))
+	for name := range sums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s  %s\n", sums[name], name)
+	}
+	return []byte(b.String())
+}
+
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
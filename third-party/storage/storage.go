@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package storage
+
+import (
+	"sync"
+
+	"github.com/happy-sdk/happy"
+	"github.com/happy-sdk/happy/sdk/settings"
+)
+
+type Settings struct {
+	Provider settings.String `key:"provider" default:"s3" mutation:"once"`
+	Bucket   settings.String `key:"bucket" mutation:"once"`
+	Endpoint settings.String `key:"endpoint" mutation:"once"`
+	Account  settings.String `key:"account" mutation:"once"`
+	Layout   settings.String `key:"layout" default:"{{ .Project }}/{{ .Version }}/{{ .File }}" mutation:"once"`
+	Public   settings.Bool   `key:"public" default:"false" mutation:"once"`
+}
+
+func (s Settings) Blueprint() (*settings.Blueprint, error) {
+	b, err := settings.New(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Storage is the API provided by the storage addon.
+type Storage struct {
+	happy.API
+
+	mu        sync.Mutex
+	publisher *Publisher
+}
+
+func Addon(s Settings) *happy.Addon {
+	addon := happy.NewAddon("storage", s)
+
+	api := &Storage{}
+	addon.ProvidesAPI(api)
+
+	addon.OnRegister(func(sess *happy.Session) error {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		backend, err := NewBackend(BackendConfig{
+			Provider: setting(sess, "provider"),
+			Bucket:   setting(sess, "bucket"),
+			Endpoint: setting(sess, "endpoint"),
+			Account:  setting(sess, "account"),
+		})
+		if err != nil {
+			return err
+		}
+		api.publisher, err = NewPublisher(backend,
+			WithLayout(setting(sess, "layout")),
+			WithPublic(setting(sess, "public") == "true"),
+		)
+		return err
+	})
+
+	return addon
+}
+
+// Publisher returns publisher uploading to the configured bucket.
+func (s *Storage) Publisher() *Publisher {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.publisher
+}
+
+func setting(sess *happy.Session, key string) string {
+	return sess.Settings().Get("storage." + key).Value().String()
+}
@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright © 2022 The Happy Authors
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type memBackend struct {
+	objects map[string]string
+	public  bool
+}
+
+func (b *memBackend) Upload(_ context.Context, path, key string, public bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	b.objects[key] = string(data)
+	b.public = public
+	return nil
+}
+
+func (b *memBackend) URL(key string) string {
+	return "mem://" + key
+}
+
+func TestPublish(t *testing.T) {
+	dir := t.TempDir()
+	var assets []string
+	for _, name := range []string{"app_linux.tar.gz", "app_darwin.tar.gz"} {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		assets = append(assets, p)
+	}
+	manifest := filepath.Join(dir, "manifest.json")
+	if err := os.WriteFile(manifest, []byte(`{}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &memBackend{objects: make(map[string]string)}
+	p, err := NewPublisher(b, WithLayout("releases/{{ .Project }}/{{ .Tag }}/{{ .File }}"), WithPublic(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	objs, err := p.Publish(context.Background(), PublishRequest{
+		Project:  "app",
+		Version:  "1.2.0",
+		Tag:      "v1.2.0",
+		Assets:   assets,
+		Manifest: manifest,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 4 || objs[3].URL != "mem://releases/app/v1.2.0/manifest.json" {
+		t.Errorf("unexpected objects %+v", objs)
+	}
+	sums := b.objects["releases/app/v1.2.0/checksums.txt"]
+	lines := strings.Split(strings.TrimSpace(sums), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "  app_darwin.tar.gz") || !strings.HasPrefix(lines[1], objs[0].SHA256) {
+		t.Errorf("unexpected checksums\n%s", sums)
+	}
+	if !b.public {
+		t.Error("expected public upload")
+	}
+}
+
+func TestPublishNameConflict(t *testing.T) {
+	dir := t.TempDir()
+	b := &memBackend{objects: make(map[string]string)}
+	p, err := NewPublisher(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []PublishRequest{
+		{Project: "app", Version: "1.2.0", Assets: []string{filepath.Join(dir, ChecksumsFile)}},
+		{Project: "app", Version: "1.2.0", Assets: []string{"linux/app.zip", "darwin/app.zip"}},
+		{Project: "app", Version: "1.2.0", Assets: []string{"manifest.json"}, Manifest: "dist/manifest.json"},
+	} {
+		if _, err := p.Publish(context.Background(), r); !errors.Is(err, Error) || !strings.Contains(err.Error(), "conflicts") {
+			t.Errorf("expected conflict of %v, got %v", r.Assets, err)
+		}
+	}
+	if len(b.objects) != 0 {
+		t.Errorf("expected nothing uploaded, got %v", b.objects)
+	}
+}
+
+func TestKeyLayout(t *testing.T) {
+	p, err := NewPublisher(&memBackend{}, WithLayout("../{{ .File }}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Key(PublishRequest{}, "x"); !errors.Is(err, Error) {
+		t.Errorf("expected key outside of bucket root to fail, got %v", err)
+	}
+	if _, err := NewPublisher(&memBackend{}, WithLayout("{{ .File ")); !errors.Is(err, Error) {
+		t.Errorf("expected invalid layout error, got %v", err)
+	}
+}
+
+func TestNewBackend(t *testing.T) {
+	b, err := NewBackend(BackendConfig{Provider: ProviderAzure, Bucket: "dist", Account: "acc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := b.URL("a/b.zip"); u != "https://acc.blob.core.windows.net/dist/a/b.zip" {
+		t.Errorf("unexpected url %q", u)
+	}
+	if err := b.Upload(context.Background(), "a.zip", "a/b.zip", true); err == nil || !strings.Contains(err.Error(), "anonymous read access") {
+		t.Errorf("expected public azure upload to fail, got %v", err)
+	}
+	if _, err := NewPublisher(b, WithPublic(true)); err == nil || !strings.Contains(err.Error(), "anonymous read access") {
+		t.Errorf("expected public azure publisher to fail, got %v", err)
+	}
+	if _, err := NewBackend(BackendConfig{Provider: "ftp", Bucket: "x"}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+}